@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"syscall"
 	"time"
@@ -19,8 +20,17 @@ import (
 var (
 	addr     = flag.String("addr", ":22017", "Endpoint for prometheus")
 	interval = flag.Duration("interval", time.Second, "Interval between statistics reports")
+
+	telemetry = flag.String("telemetry", "", "Telemetry socket of the application to retrieve lcore usage from (default is "+telemetrySocket+" in EAL runtime dir)")
 )
 
+func telemetryPath() string {
+	if *telemetry != "" {
+		return *telemetry
+	}
+	return filepath.Join(eal.RuntimeDir(), telemetrySocket)
+}
+
 func main() {
 	// gracefully shutdown
 	shutdownCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGSEGV)
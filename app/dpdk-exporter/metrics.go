@@ -6,7 +6,9 @@ import "C"
 import (
 	"context"
 	"log"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/tianyuansun/go-dpdk/eal"
 	"github.com/tianyuansun/go-dpdk/ethdev"
+	"github.com/tianyuansun/go-dpdk/mempool"
 	"github.com/tianyuansun/go-dpdk/memzone"
 	"github.com/tianyuansun/go-dpdk/ring"
 )
@@ -21,10 +24,13 @@ import (
 const (
 	portNameLbl  = "port_name"
 	memzoneLbl   = "memzone_name"
+	mempoolLbl   = "mempool_name"
+	opsNameLbl   = "ops_name"
 	macAddrLbl   = "mac_addr"
 	drvNameLbl   = "driver_name"
 	ifaceNameLbl = "interface_name"
 	xstatNameLbl = "xstat_name"
+	lcoreLbl     = "lcore_id"
 )
 const (
 	namespace    = "dpdk_exporter"
@@ -32,8 +38,10 @@ const (
 )
 
 type Metrics struct {
-	EthDev *EthDevMetrics
-	Ring   *RingMetrics
+	EthDev  *EthDevMetrics
+	Ring    *RingMetrics
+	Mempool *MempoolMetrics
+	Lcore   *LcoreMetrics
 }
 
 func NewMetrics() (m *Metrics, err error) {
@@ -43,8 +51,10 @@ func NewMetrics() (m *Metrics, err error) {
 	}
 
 	m = &Metrics{
-		EthDev: ethDev,
-		Ring:   NewRingMetrics(),
+		EthDev:  ethDev,
+		Ring:    NewRingMetrics(),
+		Mempool: NewMempoolMetrics(),
+		Lcore:   NewLcoreMetrics(telemetryPath()),
 	}
 	return
 }
@@ -56,6 +66,12 @@ func (m *Metrics) Collect() {
 	if err := m.Ring.Collect(); err != nil {
 		log.Printf("collect ring metrics: %v", err)
 	}
+	if err := m.Mempool.Collect(); err != nil {
+		log.Printf("collect mempool metrics: %v", err)
+	}
+	if err := m.Lcore.Collect(); err != nil {
+		log.Printf("collect lcore metrics: %v", err)
+	}
 }
 
 func (m *Metrics) StartCollecting(ctx context.Context) {
@@ -74,6 +90,47 @@ func (m *Metrics) StartCollecting(ctx context.Context) {
 	}
 }
 
+// CounterSnapshot exports counters sampled from DPDK. Unlike
+// prometheus.CounterVec it is set to absolute values which are
+// reported as is.
+type CounterSnapshot struct {
+	desc *prometheus.Desc
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounterSnapshot creates and registers CounterSnapshot with a
+// single variable label.
+func NewCounterSnapshot(name, help, label string) *CounterSnapshot {
+	c := &CounterSnapshot{
+		desc: prometheus.NewDesc(name, help, []string{label}, nil),
+	}
+	prometheus.MustRegister(c)
+	return c
+}
+
+// Set replaces all counter values with values keyed by label value.
+func (c *CounterSnapshot) Set(values map[string]float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = values
+}
+
+// Describe implements prometheus.Collector.
+func (c *CounterSnapshot) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector.
+func (c *CounterSnapshot) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for lbl, v := range c.values {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, v, lbl)
+	}
+}
+
 func setBooleanGauge(g prometheus.Gauge, v bool) {
 	if v {
 		g.Set(1)
@@ -367,3 +424,131 @@ func (m *RingMetrics) Collect() error {
 	})
 	return nil
 }
+
+type MempoolMetrics struct {
+	Size       *prometheus.GaugeVec
+	Avail      *prometheus.GaugeVec
+	InUse      *prometheus.GaugeVec
+	CacheSize  *prometheus.GaugeVec
+	CacheCount *prometheus.GaugeVec
+	Info       *prometheus.GaugeVec
+	GetFail    *CounterSnapshot
+}
+
+func NewMempoolMetrics() *MempoolMetrics {
+	var m MempoolMetrics
+
+	labelNames := []string{mempoolLbl}
+	const subsystem = "mempool"
+	m.Size = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "size",
+	}, labelNames)
+	m.Avail = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "avail_count",
+	}, labelNames)
+	m.InUse = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "in_use_count",
+	}, labelNames)
+	m.CacheSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "cache_size",
+	}, labelNames)
+	m.CacheCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "cache_count",
+	}, labelNames)
+	m.Info = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "info",
+	}, append(labelNames, opsNameLbl))
+	m.GetFail = NewCounterSnapshot(prometheus.BuildFQName(namespace, subsystem, "get_fail_objs_total"),
+		"Objects that failed to be allocated. Requires DPDK built with mempool stats.",
+		mempoolLbl)
+
+	return &m
+}
+
+func (m *MempoolMetrics) Collect() error {
+	getFail := map[string]float64{}
+	mempool.Walk(func(mp *mempool.Mempool) {
+		name := mp.Name()
+		labels := prometheus.Labels{mempoolLbl: name}
+
+		m.Size.With(labels).Set(float64(mp.Size()))
+		m.Avail.With(labels).Set(float64(mp.AvailCount()))
+		m.InUse.With(labels).Set(float64(mp.InUseCount()))
+		m.CacheSize.With(labels).Set(float64(mp.CacheSize()))
+		m.CacheCount.With(labels).Set(float64(mp.CacheCountTotal()))
+		m.Info.With(prometheus.Labels{
+			mempoolLbl: name,
+			opsNameLbl: mp.OpsName(),
+		}).Set(1)
+
+		var s mempool.Stats
+		if err := mp.Stats(&s); err == nil {
+			getFail[name] = float64(s.GetFailObjs)
+		}
+	})
+	m.GetFail.Set(getFail)
+	return nil
+}
+
+type LcoreMetrics struct {
+	// telemetry socket of the application
+	socket string
+
+	TotalCycles *CounterSnapshot
+	BusyCycles  *CounterSnapshot
+	BusyRatio   *prometheus.GaugeVec
+}
+
+func NewLcoreMetrics(socket string) *LcoreMetrics {
+	m := LcoreMetrics{socket: socket}
+
+	const subsystem = "lcore"
+	m.TotalCycles = NewCounterSnapshot(prometheus.BuildFQName(namespace, subsystem, "cycles_total"),
+		"TSC cycles accounted by the application polling loops.",
+		lcoreLbl)
+	m.BusyCycles = NewCounterSnapshot(prometheus.BuildFQName(namespace, subsystem, "busy_cycles_total"),
+		"TSC cycles the application polling loops did some work.",
+		lcoreLbl)
+	m.BusyRatio = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "busy_ratio",
+		Help:      "Fraction of accounted cycles the lcore was busy since the application start.",
+	}, []string{lcoreLbl})
+
+	return &m
+}
+
+// Collect retrieves lcore usage from the application through
+// /eal/lcore/usage telemetry command.
+func (m *LcoreMetrics) Collect() error {
+	usages, err := lcoreUsages(m.socket)
+	if err != nil {
+		return err
+	}
+
+	total := map[string]float64{}
+	busy := map[string]float64{}
+	m.BusyRatio.Reset()
+	for id, u := range usages {
+		lcore := strconv.FormatUint(uint64(id), 10)
+		total[lcore] = float64(u.TotalCycles)
+		busy[lcore] = float64(u.BusyCycles)
+		m.BusyRatio.With(prometheus.Labels{lcoreLbl: lcore}).Set(u.BusyRatio())
+	}
+	m.TotalCycles.Set(total)
+	m.BusyCycles.Set(busy)
+	return nil
+}
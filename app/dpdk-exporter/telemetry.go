@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/tianyuansun/go-dpdk/eal"
+)
+
+const (
+	telemetrySocket  = "dpdk_telemetry.v2"
+	telemetryTimeout = time.Second
+	lcoreUsageCmd    = "/eal/lcore/usage"
+)
+
+// queryTelemetry sends cmd to DPDK telemetry socket path and returns
+// the reply data. nil is returned if the command is not supported or
+// failed in the application.
+func queryTelemetry(path, cmd string) (json.RawMessage, error) {
+	conn, err := net.DialTimeout("unixpacket", path, telemetryTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(telemetryTimeout)); err != nil {
+		return nil, err
+	}
+
+	// upon connection the server sends its parameters
+	var info struct {
+		MaxOutputLen int `json:"max_output_len"`
+	}
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf[:n], &info); err != nil {
+		return nil, fmt.Errorf("telemetry info: %w", err)
+	}
+
+	if _, err := conn.Write([]byte(cmd)); err != nil {
+		return nil, err
+	}
+
+	if info.MaxOutputLen > len(buf) {
+		buf = make([]byte, info.MaxOutputLen)
+	}
+	if n, err = conn.Read(buf); err != nil {
+		return nil, err
+	}
+
+	var reply map[string]json.RawMessage
+	if err := json.Unmarshal(buf[:n], &reply); err != nil {
+		return nil, fmt.Errorf("telemetry reply to %s: %w", cmd, err)
+	}
+	if data := reply[cmd]; string(data) != "null" {
+		return data, nil
+	}
+	return nil, nil
+}
+
+// lcoreUsages retrieves usage of the application lcores accounted
+// with eal.UsageMeter and registered with eal.UsageRegister. It
+// requires DPDK 23.03 or later.
+func lcoreUsages(path string) (map[uint]eal.LcoreUsage, error) {
+	data, err := queryTelemetry(path, lcoreUsageCmd)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var usage struct {
+		LcoreIDs    []uint   `json:"lcore_ids"`
+		TotalCycles []uint64 `json:"total_cycles"`
+		BusyCycles  []uint64 `json:"busy_cycles"`
+	}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return nil, fmt.Errorf("%s: %w", lcoreUsageCmd, err)
+	}
+	if len(usage.TotalCycles) != len(usage.LcoreIDs) || len(usage.BusyCycles) != len(usage.LcoreIDs) {
+		return nil, fmt.Errorf("%s: malformed reply", lcoreUsageCmd)
+	}
+
+	m := make(map[uint]eal.LcoreUsage, len(usage.LcoreIDs))
+	for i, id := range usage.LcoreIDs {
+		m[id] = eal.LcoreUsage{
+			TotalCycles: usage.TotalCycles[i],
+			BusyCycles:  usage.BusyCycles[i],
+		}
+	}
+	return m, nil
+}
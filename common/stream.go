@@ -0,0 +1,33 @@
+package common
+
+/*
+#include <stdio.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"io"
+	"unsafe"
+)
+
+// DumpTo opens in-memory stdio stream and calls fn with its FILE
+// pointer. Everything written by fn into the stream is then copied
+// into w. It is used to redirect DPDK *_dump functions into Go
+// writers.
+func DumpTo(w io.Writer, fn func(fp unsafe.Pointer)) error {
+	var buf *C.char
+	var size C.size_t
+
+	fp, err := C.open_memstream(&buf, &size)
+	if fp == nil {
+		return err
+	}
+
+	fn(unsafe.Pointer(fp))
+	C.fclose(fp)
+	defer C.free(unsafe.Pointer(buf))
+
+	_, err = w.Write(C.GoBytes(unsafe.Pointer(buf), C.int(size)))
+	return err
+}
@@ -0,0 +1,6 @@
+package cycles
+
+/*
+#cgo pkg-config: libdpdk
+*/
+import "C"
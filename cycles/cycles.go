@@ -0,0 +1,97 @@
+/*
+Package cycles wraps RTE cycles API and provides cheap TSC-based
+timing utilities for polling loops.
+
+Reading TSC costs a few nanoseconds which makes it suitable for per
+burst timing where time.Now() is too expensive. TSC frequency is
+measured by EAL so all functions here should be called after EAL
+initialization.
+*/
+package cycles
+
+/*
+#include <rte_config.h>
+#include <rte_cycles.h>
+*/
+import "C"
+
+import (
+	"time"
+)
+
+// Cycles returns the current value of TSC.
+func Cycles() uint64 {
+	return uint64(C.rte_get_tsc_cycles())
+}
+
+// Hz returns the number of TSC cycles in one second.
+func Hz() uint64 {
+	return uint64(C.rte_get_tsc_hz())
+}
+
+// RdtscPrecise reads TSC with a memory barrier preventing the read
+// from being reordered with preceding loads and stores.
+func RdtscPrecise() uint64 {
+	return uint64(C.rte_rdtsc_precise())
+}
+
+// DelayUsBlock busy waits for us microseconds.
+func DelayUsBlock(us uint) {
+	C.rte_delay_us_block(C.uint(us))
+}
+
+// DelayUs waits for us microseconds using EAL delay function which
+// is busy waiting unless replaced with rte_delay_us_callback_register.
+func DelayUs(us uint) {
+	C.rte_delay_us(C.uint(us))
+}
+
+func toDuration(c, hz uint64) time.Duration {
+	return time.Duration(c/hz)*time.Second +
+		time.Duration(c%hz*uint64(time.Second)/hz)
+}
+
+func fromDuration(d time.Duration, hz uint64) uint64 {
+	if d <= 0 {
+		return 0
+	}
+	return uint64(d/time.Second)*hz + uint64(d%time.Second)*hz/uint64(time.Second)
+}
+
+// ToDuration converts c TSC cycles into time.Duration.
+func ToDuration(c uint64) time.Duration {
+	return toDuration(c, Hz())
+}
+
+// FromDuration converts d into TSC cycles. Negative durations are
+// converted to zero.
+func FromDuration(d time.Duration) uint64 {
+	return fromDuration(d, Hz())
+}
+
+// Clock is a monotonic clock driven by TSC.
+type Clock struct {
+	start uint64
+	hz    uint64
+}
+
+// NewClock creates a clock which starts at the moment of the call.
+func NewClock() *Clock {
+	return &Clock{start: Cycles(), hz: Hz()}
+}
+
+// Cycles returns the number of TSC cycles elapsed since the clock
+// start.
+func (c *Clock) Cycles() uint64 {
+	return Cycles() - c.start
+}
+
+// Now returns the time elapsed since the clock start.
+func (c *Clock) Now() time.Duration {
+	return toDuration(Cycles()-c.start, c.hz)
+}
+
+// Since returns the time elapsed since TSC value tsc.
+func (c *Clock) Since(tsc uint64) time.Duration {
+	return toDuration(Cycles()-tsc, c.hz)
+}
@@ -0,0 +1,81 @@
+package cycles
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/eal"
+)
+
+func TestCycles(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	hz := Hz()
+	assert(hz > 0)
+	assert(ToDuration(hz) == time.Second)
+	assert(ToDuration(hz*3/2) == 1500*time.Millisecond)
+	assert(FromDuration(time.Second) == hz)
+	assert(FromDuration(-time.Second) == 0)
+
+	c0 := Cycles()
+	c1 := RdtscPrecise()
+	assert(c1 >= c0, c0, c1)
+
+	clock := NewClock()
+	start := time.Now()
+	tsc := Cycles()
+	DelayUsBlock(2000)
+	assert(time.Since(start) >= 2*time.Millisecond)
+	assert(clock.Now() >= 2*time.Millisecond, clock.Now())
+	assert(clock.Since(tsc) >= 2*time.Millisecond, clock.Since(tsc))
+	assert(clock.Cycles() >= FromDuration(2*time.Millisecond))
+
+	start = time.Now()
+	DelayUs(1000)
+	assert(time.Since(start) >= time.Millisecond)
+}
+
+func TestTicker(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	tk := &Ticker{period: 10}
+	tk.Reset(100)
+	assert(!tk.TickAt(100))
+	assert(!tk.TickAt(109))
+	assert(tk.TickAt(110))
+	assert(!tk.TickAt(110))
+	assert(tk.TickAt(125))
+	assert(tk.TickAt(130))
+
+	// missed ticks are dropped
+	assert(tk.TickAt(175))
+	assert(!tk.TickAt(180))
+	assert(tk.TickAt(185))
+}
+
+func TestLimiter(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	// 1000 cycles per second, 100 events per second, bursts of 10
+	l := newLimiter(100, 10, 1000, 0)
+	assert(l.AllowAt(0, 4) == 4)
+	assert(l.AllowAt(0, 10) == 6)
+	assert(l.AllowAt(0, 1) == 0)
+
+	// one event per 10 cycles
+	assert(l.AllowAt(9, 1) == 0)
+	assert(l.AllowAt(10, 5) == 1)
+	assert(l.AllowAt(50, 5) == 4)
+
+	// bucket doesn't overflow after long idle period
+	assert(l.AllowAt(1<<62, 100) == 10)
+	assert(l.AllowAt(1<<62, 1) == 0)
+
+	// zero rate allows only the initial burst
+	l = newLimiter(0, 2, 1000, 0)
+	assert(l.AllowAt(1000000, 5) == 2)
+	assert(l.AllowAt(2000000, 5) == 0)
+}
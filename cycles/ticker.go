@@ -0,0 +1,101 @@
+package cycles
+
+import (
+	"time"
+)
+
+// Ticker signals expiration of a period in polling loops. Unlike
+// time.Ticker it has no goroutines or channels and must be polled.
+type Ticker struct {
+	period uint64
+	next   uint64
+}
+
+// NewTicker creates a ticker with period d. The first tick happens
+// after d elapses.
+func NewTicker(d time.Duration) *Ticker {
+	t := &Ticker{period: FromDuration(d)}
+	if t.period == 0 {
+		t.period = 1
+	}
+	t.Reset(Cycles())
+	return t
+}
+
+// Reset restarts the ticker so that the next tick happens one period
+// after TSC value now.
+func (t *Ticker) Reset(now uint64) {
+	t.next = now + t.period
+}
+
+// TickAt tells if the period expired at TSC value now. If the ticker
+// missed more than one period the missed ticks are dropped.
+func (t *Ticker) TickAt(now uint64) bool {
+	if now < t.next {
+		return false
+	}
+
+	if t.next += t.period; t.next <= now {
+		t.next = now + t.period
+	}
+	return true
+}
+
+// Tick is TickAt with the current TSC value.
+func (t *Ticker) Tick() bool {
+	return t.TickAt(Cycles())
+}
+
+// Limiter is a token bucket rate limiter driven by TSC. It allows
+// up to burst events at once and rate events per second on average.
+// It is not safe for concurrent use and is meant to be owned by a
+// single lcore.
+type Limiter struct {
+	rate  uint64 // events per second
+	hz    uint64
+	max   uint64 // maximum credit
+	avail uint64 // credit in events multiplied by hz
+	last  uint64
+}
+
+// NewLimiter creates a limiter allowing rate events per second with
+// bursts of up to burst events. The bucket is initially full.
+func NewLimiter(rate, burst uint64) *Limiter {
+	return newLimiter(rate, burst, Hz(), Cycles())
+}
+
+func newLimiter(rate, burst, hz, now uint64) *Limiter {
+	l := &Limiter{rate: rate, hz: hz, max: burst * hz}
+	l.avail = l.max
+	l.last = now
+	return l
+}
+
+// AllowAt returns the number of events out of n allowed at TSC value
+// now and consumes them.
+func (l *Limiter) AllowAt(now uint64, n uint64) uint64 {
+	if now > l.last {
+		if l.rate != 0 {
+			// avoid overflow after long idle periods
+			elapsed := now - l.last
+			if lim := l.max/l.rate + 1; elapsed > lim {
+				elapsed = lim
+			}
+			if l.avail += elapsed * l.rate; l.avail > l.max {
+				l.avail = l.max
+			}
+		}
+		l.last = now
+	}
+
+	if k := l.avail / l.hz; n > k {
+		n = k
+	}
+	l.avail -= n * l.hz
+	return n
+}
+
+// Allow is AllowAt with the current TSC value.
+func (l *Limiter) Allow(n uint64) uint64 {
+	return l.AllowAt(Cycles(), n)
+}
@@ -0,0 +1,6 @@
+package dev
+
+/*
+#cgo pkg-config: libdpdk
+*/
+import "C"
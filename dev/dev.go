@@ -0,0 +1,220 @@
+/*
+Package dev wraps RTE generic device API: hotplug of devices and
+virtual devices and device event monitoring.
+
+Devices may be added and removed at runtime without restarting EAL.
+Use ethdev.Attach and Port.Detach to manage Ethernet ports.
+*/
+package dev
+
+/*
+#define ALLOW_EXPERIMENTAL_API
+#include <stdlib.h>
+
+#include <rte_config.h>
+#include <rte_version.h>
+#include <rte_dev.h>
+#include <rte_bus_vdev.h>
+
+extern void goDevEventCb(char *name, enum rte_dev_event_type event, void *arg);
+
+static void dev_event_cb(const char *name, enum rte_dev_event_type event, void *arg) {
+	goDevEventCb((char *)name, event, arg);
+}
+
+static int dev_event_callback_register(const char *name, void *arg) {
+	return rte_dev_event_callback_register(name, dev_event_cb, arg);
+}
+
+static int dev_event_callback_unregister(const char *name, void *arg) {
+	return rte_dev_event_callback_unregister(name, dev_event_cb, arg);
+}
+
+static const char *dev_name(const struct rte_device *dev) {
+#if RTE_VERSION >= RTE_VERSION_NUM(22, 11, 0, 0)
+	return rte_dev_name(dev);
+#else
+	return dev->name;
+#endif
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// Device is a generic device handled by a bus driver.
+type Device C.struct_rte_device
+
+// EventType is a device event type.
+type EventType uint32
+
+// Device event types.
+const (
+	EventAdd    EventType = C.RTE_DEV_EVENT_ADD
+	EventRemove EventType = C.RTE_DEV_EVENT_REMOVE
+)
+
+// String implements fmt.Stringer.
+func (t EventType) String() string {
+	switch t {
+	case EventAdd:
+		return "ADD"
+	case EventRemove:
+		return "REMOVE"
+	}
+	return "UNKNOWN"
+}
+
+// Event is a device event reported by the event monitor.
+type Event struct {
+	Name string
+	Type EventType
+}
+
+// EventCallback is a registered event channel.
+type EventCallback struct {
+	name *C.char
+	arg  *common.ObjectID
+}
+
+var (
+	eventChans = common.NewRegistryArray()
+)
+
+func err(n ...interface{}) error {
+	if len(n) == 0 {
+		return common.RteErrno()
+	}
+
+	return common.IntToErr(n[0])
+}
+
+// Probe probes and attaches a device specified by devargs, e.g.
+// "0000:02:00.0" or "net_null0,size=64". Bus name may be prepended
+// with a colon, e.g. "vdev:net_null0".
+func Probe(devargs string) error {
+	cargs := C.CString(devargs)
+	defer C.free(unsafe.Pointer(cargs))
+	return err(C.rte_dev_probe(cargs))
+}
+
+// Remove detaches the device from its driver and removes it from
+// the bus. All ports of the device must be closed beforehand.
+func (d *Device) Remove() error {
+	return err(C.rte_dev_remove((*C.struct_rte_device)(d)))
+}
+
+// Name returns the name of the device.
+func (d *Device) Name() string {
+	return C.GoString(C.dev_name((*C.struct_rte_device)(d)))
+}
+
+// HotplugAdd probes and attaches device name with arguments args on
+// specified bus, e.g. "pci" or "vdev".
+func HotplugAdd(bus, name, args string) error {
+	cbus := C.CString(bus)
+	defer C.free(unsafe.Pointer(cbus))
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	cargs := C.CString(args)
+	defer C.free(unsafe.Pointer(cargs))
+	return err(C.rte_eal_hotplug_add(cbus, cname, cargs))
+}
+
+// HotplugRemove detaches and removes device name from specified bus.
+func HotplugRemove(bus, name string) error {
+	cbus := C.CString(bus)
+	defer C.free(unsafe.Pointer(cbus))
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return err(C.rte_eal_hotplug_remove(cbus, cname))
+}
+
+// VdevInit creates and initializes virtual device name with
+// arguments args, e.g. VdevInit("net_ring0", "nodeaction=r0:0:CREATE").
+func VdevInit(name, args string) error {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	cargs := C.CString(args)
+	defer C.free(unsafe.Pointer(cargs))
+	return err(C.rte_vdev_init(cname, cargs))
+}
+
+// VdevUninit uninitializes and removes virtual device name.
+func VdevUninit(name string) error {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return err(C.rte_vdev_uninit(cname))
+}
+
+// EventMonitorStart starts monitoring device events from the kernel,
+// e.g. removal of PCI devices.
+func EventMonitorStart() error {
+	return err(C.rte_dev_event_monitor_start())
+}
+
+// EventMonitorStop stops monitoring device events.
+func EventMonitorStop() error {
+	return err(C.rte_dev_event_monitor_stop())
+}
+
+// HotplugHandleEnable enables handling of hot-unplugged devices by
+// EAL, i.e. failure handling of removed devices which are still in
+// use.
+func HotplugHandleEnable() error {
+	return err(C.rte_dev_hotplug_handle_enable())
+}
+
+// HotplugHandleDisable disables handling of hot-unplugged devices.
+func HotplugHandleDisable() error {
+	return err(C.rte_dev_hotplug_handle_disable())
+}
+
+// RegisterEventChan delivers events of device name into ch. If name
+// is empty, events of all devices are delivered. The events are sent
+// from EAL interrupt thread without blocking so they are dropped if
+// ch is full.
+//
+// Events are generated only if the monitor is started with
+// EventMonitorStart.
+func RegisterEventChan(name string, ch chan<- Event) (*EventCallback, error) {
+	cb := &EventCallback{}
+	if name != "" {
+		cb.name = C.CString(name)
+	}
+
+	obj := eventChans.Create(ch)
+	cb.arg = (*common.ObjectID)(C.malloc(C.size_t(unsafe.Sizeof(obj))))
+	*cb.arg = obj
+
+	if e := err(C.dev_event_callback_register(cb.name, unsafe.Pointer(cb.arg))); e != nil {
+		cb.free()
+		return nil, e
+	}
+	return cb, nil
+}
+
+func (cb *EventCallback) free() {
+	eventChans.Delete(*cb.arg)
+	C.free(unsafe.Pointer(cb.arg))
+	C.free(unsafe.Pointer(cb.name))
+	cb.arg, cb.name = nil, nil
+}
+
+// Unregister stops delivery of events into the channel. The channel
+// is not closed. Repeated calls have no effect.
+func (cb *EventCallback) Unregister() error {
+	if cb.arg == nil {
+		return nil
+	}
+
+	if n := C.dev_event_callback_unregister(cb.name, unsafe.Pointer(cb.arg)); n < 0 {
+		return err(n)
+	}
+	cb.free()
+	return nil
+}
@@ -0,0 +1,83 @@
+package dev_test
+
+import (
+	"testing"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/dev"
+	"github.com/tianyuansun/go-dpdk/eal"
+	"github.com/tianyuansun/go-dpdk/ethdev"
+)
+
+func TestVdev(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	n := ethdev.CountAvail()
+	assert(dev.VdevInit("net_null_dev0", "size=128") == nil)
+	assert(ethdev.CountAvail() == n+1)
+
+	pid, err := ethdev.GetPortByName("net_null_dev0")
+	assert(err == nil, err)
+	d, err := pid.Device()
+	assert(err == nil, err)
+	assert(d.Name() == "net_null_dev0", d.Name())
+
+	assert(dev.VdevInit("net_null_dev0", "") != nil)
+	assert(dev.VdevUninit("net_null_dev0") == nil)
+	assert(ethdev.CountAvail() == n)
+	assert(!pid.IsValid())
+	assert(dev.VdevUninit("net_null_dev0") != nil)
+
+	assert(dev.VdevInit("no_such_driver0", "") != nil)
+}
+
+func TestHotplug(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	assert(dev.HotplugAdd("vdev", "net_null_dev1", "") == nil)
+	_, err := ethdev.GetPortByName("net_null_dev1")
+	assert(err == nil, err)
+	assert(dev.HotplugRemove("vdev", "net_null_dev1") == nil)
+	_, err = ethdev.GetPortByName("net_null_dev1")
+	assert(err != nil)
+
+	assert(dev.Probe("vdev:net_null_dev2") == nil)
+	pid, err := ethdev.GetPortByName("net_null_dev2")
+	assert(err == nil, err)
+	d, err := pid.Device()
+	assert(err == nil, err)
+	pid.Close()
+	assert(d.Remove() == nil)
+	assert(!pid.IsValid())
+
+	assert(dev.Probe("no_such_bus:dev0") != nil)
+}
+
+func TestEventMonitor(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	assert(dev.EventAdd.String() == "ADD")
+	assert(dev.EventRemove.String() == "REMOVE")
+
+	ch := make(chan dev.Event, 4)
+	cb, err := dev.RegisterEventChan("", ch)
+	assert(err == nil, err)
+	defer cb.Unregister()
+
+	cb1, err := dev.RegisterEventChan("0000:00:01.0", ch)
+	assert(err == nil, err)
+	assert(cb1.Unregister() == nil)
+	assert(cb1.Unregister() == nil)
+
+	// kernel uevent socket may be unavailable in the sandbox
+	if err := dev.EventMonitorStart(); err != nil {
+		t.Skip("event monitor is not available:", err)
+	}
+	assert(dev.EventMonitorStop() == nil)
+}
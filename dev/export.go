@@ -0,0 +1,22 @@
+package dev
+
+/*
+#include <rte_config.h>
+#include <rte_dev.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+//export goDevEventCb
+func goDevEventCb(name *C.char, event C.enum_rte_dev_event_type, arg unsafe.Pointer) {
+	ch := eventChans.Read(*(*common.ObjectID)(arg)).(chan<- Event)
+	select {
+	case ch <- Event{C.GoString(name), EventType(event)}:
+	default:
+	}
+}
@@ -0,0 +1,345 @@
+package eal
+
+/*
+#define _GNU_SOURCE
+#include <sched.h>
+
+#include <rte_config.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IOVA modes for Config.IOVAMode.
+const (
+	IOVAModeDefault = ""
+	IOVAModePA      = "pa"
+	IOVAModeVA      = "va"
+)
+
+// Process types for Config.ProcType.
+const (
+	ProcTypeDefault   = ""
+	ProcTypePrimary   = "primary"
+	ProcTypeSecondary = "secondary"
+	ProcTypeAuto      = "auto"
+)
+
+// LogLevel sets log level for log types matching Pattern. Empty
+// Pattern sets global log level. Pattern is a shell glob matched with
+// fnmatch, e.g. "lib.eal" or "pmd.net.*", unless Regexp is set in
+// which case it's a POSIX regular expression. Level is either a name
+// (e.g. "debug", "notice") or a number from 1 to 8.
+type LogLevel struct {
+	Pattern string `json:"pattern,omitempty"`
+	Regexp  bool   `json:"regexp,omitempty"`
+	Level   string `json:"level"`
+}
+
+// Config is a structured EAL configuration. It may be loaded from
+// application's configuration files and rendered into EAL arguments
+// with Args, or passed directly to InitConfig.
+//
+// Zero value of a field means EAL default for corresponding option.
+type Config struct {
+	// Program is argv[0] passed to EAL. If empty, os.Args[0] is
+	// used.
+	Program string `json:"program,omitempty"`
+
+	// Lcores is a list of lcore ids to run on, each lcore bound to
+	// the CPU with the same id (-l).
+	Lcores []uint `json:"lcores,omitempty"`
+
+	// LcoreMap maps lcore ids to sets of CPUs lcore threads are
+	// bound to (--lcores). Mutually exclusive with Lcores.
+	LcoreMap map[uint][]uint `json:"lcore_map,omitempty"`
+
+	// MainLcore is the id of main lcore (--main-lcore).
+	MainLcore *uint `json:"main_lcore,omitempty"`
+
+	// Memory is the amount of memory to preallocate at startup in
+	// megabytes (-m).
+	Memory uint `json:"memory,omitempty"`
+
+	// SocketMem is the amount of memory to preallocate on each
+	// NUMA socket in megabytes (--socket-mem). Mutually exclusive
+	// with Memory.
+	SocketMem []uint `json:"socket_mem,omitempty"`
+
+	// NoHuge disables use of hugepages (--no-huge).
+	NoHuge bool `json:"no_huge,omitempty"`
+
+	// InMemory disables creation of any shared data structures
+	// and files on the filesystem (--in-memory).
+	InMemory bool `json:"in_memory,omitempty"`
+
+	// NoPCI disables PCI bus (--no-pci).
+	NoPCI bool `json:"no_pci,omitempty"`
+
+	// FilePrefix is the prefix for hugepage and runtime files
+	// (--file-prefix).
+	FilePrefix string `json:"file_prefix,omitempty"`
+
+	// Vdevs is a list of virtual devices to add, e.g. "net_null0"
+	// or "net_ring0,nodeaction=r1:0:CREATE" (--vdev).
+	Vdevs []string `json:"vdevs,omitempty"`
+
+	// Allow is a list of devices to probe exclusively (-a).
+	Allow []string `json:"allow,omitempty"`
+
+	// Block is a list of devices not to probe (-b). Mutually
+	// exclusive with Allow.
+	Block []string `json:"block,omitempty"`
+
+	// IOVAMode forces IOVA mode, see IOVAMode* constants
+	// (--iova-mode).
+	IOVAMode string `json:"iova_mode,omitempty"`
+
+	// ProcType is the type of this process, see ProcType*
+	// constants (--proc-type).
+	ProcType string `json:"proc_type,omitempty"`
+
+	// LogLevels is a list of log levels applied in order
+	// (--log-level).
+	LogLevels []LogLevel `json:"log_levels,omitempty"`
+
+	// Extra is a list of arguments appended to rendered arguments
+	// as is.
+	Extra []string `json:"extra,omitempty"`
+}
+
+var logLevelNames = []string{
+	"emergency", "alert", "critical", "error",
+	"warning", "notice", "info", "debug",
+}
+
+func checkLogLevel(s string) bool {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n >= 1 && n <= len(logLevelNames)
+	}
+	for _, name := range logLevelNames {
+		// EAL accepts any prefix of a level name
+		if s != "" && strings.HasPrefix(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkLcore(id uint) error {
+	if id >= C.RTE_MAX_LCORE {
+		return fmt.Errorf("lcore id %d exceeds %d", id, C.RTE_MAX_LCORE-1)
+	}
+	return nil
+}
+
+func configErr(field string, format string, a ...interface{}) error {
+	return fmt.Errorf("eal config: %s: %s", field, fmt.Sprintf(format, a...))
+}
+
+func (cfg *Config) validateLcores() error {
+	if len(cfg.Lcores) != 0 && len(cfg.LcoreMap) != 0 {
+		return configErr("Lcores", "mutually exclusive with LcoreMap")
+	}
+
+	lcores := map[uint]bool{}
+	for _, id := range cfg.Lcores {
+		if err := checkLcore(id); err != nil {
+			return configErr("Lcores", "%v", err)
+		}
+		if lcores[id] {
+			return configErr("Lcores", "duplicate lcore %d", id)
+		}
+		lcores[id] = true
+	}
+
+	for id, cpus := range cfg.LcoreMap {
+		if err := checkLcore(id); err != nil {
+			return configErr("LcoreMap", "%v", err)
+		}
+		if len(cpus) == 0 {
+			return configErr("LcoreMap", "no CPUs specified for lcore %d", id)
+		}
+		for _, cpu := range cpus {
+			if cpu >= C.CPU_SETSIZE {
+				return configErr("LcoreMap", "CPU %d exceeds %d", cpu, C.CPU_SETSIZE-1)
+			}
+		}
+		lcores[id] = true
+	}
+
+	if cfg.MainLcore != nil {
+		if err := checkLcore(*cfg.MainLcore); err != nil {
+			return configErr("MainLcore", "%v", err)
+		}
+		if len(lcores) != 0 && !lcores[*cfg.MainLcore] {
+			return configErr("MainLcore", "lcore %d is not in the lcore set", *cfg.MainLcore)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the configuration is consistent. It doesn't
+// guarantee that EAL initialization succeeds since the availability
+// of CPUs, memory and devices is only known to EAL.
+func (cfg *Config) Validate() error {
+	if err := cfg.validateLcores(); err != nil {
+		return err
+	}
+
+	if cfg.Memory != 0 && len(cfg.SocketMem) != 0 {
+		return configErr("SocketMem", "mutually exclusive with Memory")
+	}
+
+	if cfg.NoHuge && len(cfg.SocketMem) != 0 {
+		return configErr("SocketMem", "cannot be used with NoHuge")
+	}
+
+	if strings.ContainsAny(cfg.FilePrefix, "/%") {
+		return configErr("FilePrefix", "invalid prefix %q", cfg.FilePrefix)
+	}
+
+	if len(cfg.Allow) != 0 && len(cfg.Block) != 0 {
+		return configErr("Block", "mutually exclusive with Allow")
+	}
+
+	for _, name := range cfg.Vdevs {
+		if name == "" {
+			return configErr("Vdevs", "empty device name")
+		}
+	}
+
+	switch cfg.IOVAMode {
+	case IOVAModeDefault, IOVAModePA, IOVAModeVA:
+	default:
+		return configErr("IOVAMode", "unknown mode %q", cfg.IOVAMode)
+	}
+
+	switch cfg.ProcType {
+	case ProcTypeDefault, ProcTypePrimary, ProcTypeSecondary, ProcTypeAuto:
+	default:
+		return configErr("ProcType", "unknown process type %q", cfg.ProcType)
+	}
+
+	for _, l := range cfg.LogLevels {
+		if !checkLogLevel(l.Level) {
+			return configErr("LogLevels", "invalid level %q", l.Level)
+		}
+		if l.Regexp && l.Pattern == "" {
+			return configErr("LogLevels", "empty regular expression")
+		}
+	}
+
+	return nil
+}
+
+func joinUint(a []uint, sep string) string {
+	s := make([]string, len(a))
+	for i, n := range a {
+		s[i] = strconv.FormatUint(uint64(n), 10)
+	}
+	return strings.Join(s, sep)
+}
+
+func (cfg *Config) lcoreMapArg() string {
+	ids := make([]uint, 0, len(cfg.LcoreMap))
+	for id := range cfg.LcoreMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = fmt.Sprintf("%d@(%s)", id, joinUint(cfg.LcoreMap[id], ","))
+	}
+	return strings.Join(s, ",")
+}
+
+// Args validates the configuration and renders it into EAL
+// arguments suitable for Init, including argv[0].
+func (cfg *Config) Args() ([]string, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	argv := []string{cfg.Program}
+	if argv[0] == "" {
+		argv[0] = os.Args[0]
+	}
+
+	add := func(args ...string) { argv = append(argv, args...) }
+
+	if len(cfg.Lcores) != 0 {
+		add("-l", joinUint(cfg.Lcores, ","))
+	}
+	if len(cfg.LcoreMap) != 0 {
+		add("--lcores", cfg.lcoreMapArg())
+	}
+	if cfg.MainLcore != nil {
+		add("--main-lcore", strconv.FormatUint(uint64(*cfg.MainLcore), 10))
+	}
+	if cfg.Memory != 0 {
+		add("-m", strconv.FormatUint(uint64(cfg.Memory), 10))
+	}
+	if len(cfg.SocketMem) != 0 {
+		add("--socket-mem", joinUint(cfg.SocketMem, ","))
+	}
+	if cfg.NoHuge {
+		add("--no-huge")
+	}
+	if cfg.InMemory {
+		add("--in-memory")
+	}
+	if cfg.NoPCI {
+		add("--no-pci")
+	}
+	if cfg.FilePrefix != "" {
+		add("--file-prefix", cfg.FilePrefix)
+	}
+	for _, dev := range cfg.Vdevs {
+		add("--vdev", dev)
+	}
+	for _, dev := range cfg.Allow {
+		add("-a", dev)
+	}
+	for _, dev := range cfg.Block {
+		add("-b", dev)
+	}
+	if cfg.IOVAMode != "" {
+		add("--iova-mode", cfg.IOVAMode)
+	}
+	if cfg.ProcType != "" {
+		add("--proc-type", cfg.ProcType)
+	}
+	for _, l := range cfg.LogLevels {
+		switch {
+		case l.Pattern == "":
+			add("--log-level", l.Level)
+		case l.Regexp:
+			add("--log-level", l.Pattern+","+l.Level)
+		default:
+			add("--log-level", l.Pattern+":"+l.Level)
+		}
+	}
+
+	return append(argv, cfg.Extra...), nil
+}
+
+// InitConfig initializes EAL as in rte_eal_init with arguments
+// rendered from cfg. Invalid configuration is reported without
+// calling rte_eal_init.
+func InitConfig(cfg *Config) error {
+	argv, err := cfg.Args()
+	if err != nil {
+		return err
+	}
+	_, err = Init(argv)
+	return err
+}
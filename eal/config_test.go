@@ -0,0 +1,86 @@
+package eal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+func TestConfigArgs(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	main := uint(1)
+	cfg := &Config{
+		Program:    "test",
+		LcoreMap:   map[uint][]uint{2: {3, 4}, 1: {0}},
+		MainLcore:  &main,
+		Memory:     128,
+		NoHuge:     true,
+		InMemory:   true,
+		NoPCI:      true,
+		FilePrefix: "cfg",
+		Vdevs:      []string{"net_null0", "net_null1"},
+		Block:      []string{"0000:00:01.0"},
+		IOVAMode:   IOVAModeVA,
+		ProcType:   ProcTypePrimary,
+		LogLevels: []LogLevel{
+			{Level: "notice"},
+			{Pattern: "lib.eal", Level: "debug"},
+			{Pattern: "^pmd\\.net", Regexp: true, Level: "info"},
+		},
+		Extra: []string{"--no-telemetry"},
+	}
+
+	argv, err := cfg.Args()
+	assert(err == nil, err)
+	expected := "test --lcores 1@(0),2@(3,4) --main-lcore 1 -m 128 " +
+		"--no-huge --in-memory --no-pci --file-prefix cfg " +
+		"--vdev net_null0 --vdev net_null1 -b 0000:00:01.0 " +
+		"--iova-mode va --proc-type primary " +
+		"--log-level notice --log-level lib.eal:debug " +
+		"--log-level ^pmd\\.net,info --no-telemetry"
+	assert(strings.Join(argv, " ") == expected, argv)
+
+	cfg = &Config{Lcores: []uint{0, 1, 2}, SocketMem: []uint{512, 0}, Allow: []string{"net_null0"}}
+	argv, err = cfg.Args()
+	assert(err == nil, err)
+	assert(strings.Join(argv[1:], " ") == "-l 0,1,2 --socket-mem 512,0 -a net_null0", argv)
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	main := uint(3)
+	for i, cfg := range []*Config{
+		{Lcores: []uint{0}, LcoreMap: map[uint][]uint{1: {1}}},
+		{Lcores: []uint{0, 0}},
+		{Lcores: []uint{1 << 20}},
+		{LcoreMap: map[uint][]uint{1: {}}},
+		{LcoreMap: map[uint][]uint{1: {1 << 20}}},
+		{Lcores: []uint{0, 1}, MainLcore: &main},
+		{Memory: 128, SocketMem: []uint{128}},
+		{NoHuge: true, SocketMem: []uint{128}},
+		{FilePrefix: "a/b"},
+		{Allow: []string{"a"}, Block: []string{"b"}},
+		{Vdevs: []string{""}},
+		{IOVAMode: "dma"},
+		{ProcType: "tertiary"},
+		{LogLevels: []LogLevel{{Level: "verbose"}}},
+		{LogLevels: []LogLevel{{Level: "9"}}},
+		{LogLevels: []LogLevel{{Regexp: true, Level: "debug"}}},
+	} {
+		assert(cfg.Validate() != nil, i)
+		_, err := cfg.Args()
+		assert(err != nil, i)
+	}
+
+	for i, cfg := range []*Config{
+		{},
+		{Lcores: []uint{0, 3}, MainLcore: &main},
+		{MainLcore: &main},
+		{LogLevels: []LogLevel{{Level: "8"}, {Pattern: "pmd.*", Level: "warn"}}},
+	} {
+		assert(cfg.Validate() == nil, i)
+	}
+}
@@ -5,7 +5,7 @@ function which essentially receives functions to execute via Go channel. So you
 may run arbitrary Go code in the context of EAL thread.
 
 EAL may be initialized via command line string, parsed command line string or a
-set of Options.
+structured Config which is validated and rendered into EAL arguments.
 
 Please note that some functions may be called only in EAL thread because of TLS
 (Thread Local Storage) dependency.
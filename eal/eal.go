@@ -7,7 +7,10 @@ package eal
 #include <rte_lcore.h>
 */
 import "C"
-import "unsafe"
+import (
+	"context"
+	"unsafe"
+)
 
 const (
 	// PmdPath is the default location of shared objects to load by
@@ -16,7 +19,8 @@ const (
 )
 
 // StopLcores sends signal to EAL threads to finish execution of
-// go-dpdk lcore function executor.
+// go-dpdk lcore function executor. Contexts of currently running jobs
+// are cancelled.
 //
 // Warning: it will block until all lcore threads finish execution.
 func StopLcores() {
@@ -24,6 +28,7 @@ func StopLcores() {
 	ch := make(chan error, len(lcores))
 
 	for _, id := range lcores {
+		StopLcoreJob(id)
 		ExecOnLcoreAsync(id, ch, func(ctx *LcoreCtx) {
 			ctx.done = true
 		})
@@ -45,12 +50,7 @@ func StopLcores() {
 // The function returns ret. You may specify ret to be nil, in which
 // case no error will be reported.
 func ExecOnLcoreAsync(lcoreID uint, ret chan error, fn func(*LcoreCtx)) <-chan error {
-	if ctx, ok := goEAL.lcores[lcoreID]; ok {
-		ctx.ch <- &lcoreJob{fn, ret}
-	} else if ret != nil {
-		ret <- ErrLcoreInvalid
-	}
-	return ret
+	return ExecOnLcoreCtxAsync(context.Background(), lcoreID, ret, fn)
 }
 
 // ExecOnLcore sends fn to execute on CPU logical core lcoreID, i.e.
@@ -134,6 +134,12 @@ func GetMainLcore() uint {
 	return uint(C.rte_get_main_lcore())
 }
 
+// RuntimeDir returns the directory where EAL keeps runtime files of
+// the process group, e.g. telemetry sockets.
+func RuntimeDir() string {
+	return C.GoString(C.rte_eal_get_runtime_dir())
+}
+
 // PrimaryProcAlive checks if a primary process is currently alive.
 func PrimaryProcAlive(path string) bool {
 	var s *C.char
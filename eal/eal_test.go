@@ -90,6 +90,15 @@ func TestEALInit(t *testing.T) {
 	// invalid lcore
 	assert(ExecOnLcore(uint(1024), func(ctx *LcoreCtx) {}) == ErrLcoreInvalid)
 
+	// job contexts and introspection
+	testLcoreJobs(t)
+
+	// non-EAL lcores
+	testLcoreThreads(t)
+
+	// usage accounting
+	testLcoreUsage(t)
+
 	// stop all lcores
 	StopLcores()
 
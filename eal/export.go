@@ -0,0 +1,35 @@
+package eal
+
+/*
+#include <stdint.h>
+
+#include <rte_config.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+//export goLcoreIterCb
+func goLcoreIterCb(id C.uint, arg unsafe.Pointer) C.int {
+	cb := *(*common.ObjectID)(arg)
+	fn := lcoreIterCallbacks.Read(cb).(func(uint) bool)
+	if fn(uint(id)) {
+		return 0
+	}
+	return 1
+}
+
+//export goLcoreUsage
+func goLcoreUsage(id C.uint, total, busy *C.uint64_t) C.int {
+	u, ok := GetLcoreUsage(uint(id))
+	if !ok {
+		return -1
+	}
+	*total = C.uint64_t(u.TotalCycles)
+	*busy = C.uint64_t(u.BusyCycles)
+	return 0
+}
@@ -13,6 +13,7 @@ extern int lcoreFuncListener(void *arg);
 import "C"
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -45,6 +46,7 @@ var (
 type lcoreJob struct {
 	fn  func(*LcoreCtx)
 	ret chan<- error
+	ctx context.Context
 }
 
 // LcoreCtx is a per-lcore context and is supplied to function running to
@@ -60,6 +62,10 @@ type LcoreCtx struct {
 
 	// signal to kill current thread
 	done bool
+
+	// currently running job
+	mu  sync.Mutex
+	job *runningJob
 }
 
 type ealConfig struct {
@@ -205,12 +211,7 @@ func lcoreFuncListener(arg unsafe.Pointer) C.int {
 
 	// run loop
 	for job := range ctx.ch {
-		var err error
-		if PanicAsErr {
-			err = panicCatcher(job.fn, ctx)
-		} else {
-			job.fn(ctx)
-		}
+		err := ctx.run(job)
 		if job.ret != nil {
 			job.ret <- err
 		}
@@ -0,0 +1,206 @@
+package eal
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// ErrLcoreIdle is returned by StopLcoreJob and RestartLcoreJob in
+// case no job is running on the desired lcore.
+var ErrLcoreIdle = fmt.Errorf("No job running on logical core")
+
+type runningJob struct {
+	*lcoreJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	since  time.Time
+}
+
+// JobInfo describes the state of an lcore executor.
+type JobInfo struct {
+	// LcoreID is the lcore id.
+	LcoreID uint
+
+	// Running tells if the lcore is executing a job.
+	Running bool
+
+	// Func is the name of the running function.
+	Func string
+
+	// Since is the time the running job started.
+	Since time.Time
+
+	// Queued is the number of jobs waiting for execution.
+	Queued int
+}
+
+// String implements fmt.Stringer.
+func (info JobInfo) String() string {
+	if !info.Running {
+		return fmt.Sprintf("lcore=%d idle queued=%d", info.LcoreID, info.Queued)
+	}
+	return fmt.Sprintf("lcore=%d func=%s since=%s queued=%d", info.LcoreID,
+		info.Func, info.Since.Format(time.RFC3339), info.Queued)
+}
+
+// Context returns the context of currently running job. It is
+// cancelled when the parent context specified in ExecOnLcoreCtx is
+// done, the job is stopped with StopLcoreJob or RestartLcoreJob, or
+// the job returns.
+//
+// Long-running lcore functions such as polling loops should watch it
+// to finish gracefully.
+func (ctx *LcoreCtx) Context() context.Context {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.job == nil {
+		return context.Background()
+	}
+	return ctx.job.ctx
+}
+
+// Stopped tells if the context of currently running job is done. It
+// is a cheap non-blocking check to use in polling loops.
+func (ctx *LcoreCtx) Stopped() bool {
+	select {
+	case <-ctx.Context().Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func (ctx *LcoreCtx) run(job *lcoreJob) error {
+	if err := job.ctx.Err(); err != nil {
+		return err
+	}
+
+	jobCtx, cancel := context.WithCancel(job.ctx)
+	defer cancel()
+
+	ctx.mu.Lock()
+	ctx.job = &runningJob{job, jobCtx, cancel, time.Now()}
+	ctx.mu.Unlock()
+
+	defer func() {
+		ctx.mu.Lock()
+		ctx.job = nil
+		ctx.mu.Unlock()
+	}()
+
+	if PanicAsErr {
+		return panicCatcher(job.fn, ctx)
+	}
+	job.fn(ctx)
+	return nil
+}
+
+// ExecOnLcoreCtxAsync is ExecOnLcoreAsync with the job bound to
+// parent context. If parent is done before the job starts, the job
+// is not executed and parent's error is returned through ret.
+// Otherwise, the job may access derived context via
+// LcoreCtx.Context.
+func ExecOnLcoreCtxAsync(parent context.Context, lcoreID uint, ret chan error, fn func(*LcoreCtx)) <-chan error {
+	if ctx, ok := goEAL.lcores[lcoreID]; ok {
+		ctx.ch <- &lcoreJob{fn, ret, parent}
+	} else if ret != nil {
+		ret <- ErrLcoreInvalid
+	}
+	return ret
+}
+
+// ExecOnLcoreCtx is ExecOnLcore with the job bound to parent
+// context. See ExecOnLcoreCtxAsync.
+func ExecOnLcoreCtx(parent context.Context, lcoreID uint, fn func(*LcoreCtx)) error {
+	return <-ExecOnLcoreCtxAsync(parent, lcoreID, make(chan error, 1), fn)
+}
+
+func lcoreRunningJob(lcoreID uint) (*runningJob, error) {
+	ctx, ok := goEAL.lcores[lcoreID]
+	if !ok {
+		return nil, ErrLcoreInvalid
+	}
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.job == nil {
+		return nil, ErrLcoreIdle
+	}
+	return ctx.job, nil
+}
+
+// StopLcoreJob cancels the context of the job running on lcore
+// lcoreID. It doesn't wait for the job to return.
+func StopLcoreJob(lcoreID uint) error {
+	job, err := lcoreRunningJob(lcoreID)
+	if err != nil {
+		return err
+	}
+	job.cancel()
+	return nil
+}
+
+// RestartLcoreJob gracefully restarts the job running on lcore
+// lcoreID: its context is cancelled and the same function is
+// executed again with the same parent context once it returns. The
+// result of the first execution is reported as usual and the result
+// of the new execution is sent to ret.
+//
+// Please note that jobs queued on the lcore before the call are
+// executed before the restarted job.
+func RestartLcoreJob(lcoreID uint, ret chan error) error {
+	job, err := lcoreRunningJob(lcoreID)
+	if err != nil {
+		return err
+	}
+	job.cancel()
+	ExecOnLcoreCtxAsync(job.lcoreJob.ctx, lcoreID, ret, job.fn)
+	return nil
+}
+
+func funcName(fn interface{}) string {
+	if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+		return f.Name()
+	}
+	return "unknown"
+}
+
+// LcoreJob returns the state of lcore lcoreID executor.
+func LcoreJob(lcoreID uint) (JobInfo, error) {
+	ctx, ok := goEAL.lcores[lcoreID]
+	if !ok {
+		return JobInfo{}, ErrLcoreInvalid
+	}
+
+	info := JobInfo{LcoreID: lcoreID, Queued: len(ctx.ch)}
+
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if job := ctx.job; job != nil {
+		info.Running = true
+		info.Func = funcName(job.fn)
+		info.Since = job.since
+	}
+	return info, nil
+}
+
+// Jobs returns the state of all lcore executors sorted by lcore id.
+func Jobs() []JobInfo {
+	ids := make([]uint, 0, len(goEAL.lcores))
+	for id := range goEAL.lcores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	jobs := make([]JobInfo, 0, len(ids))
+	for _, id := range ids {
+		if info, err := LcoreJob(id); err == nil {
+			jobs = append(jobs, info)
+		}
+	}
+	return jobs
+}
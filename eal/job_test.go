@@ -0,0 +1,95 @@
+package eal
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+func pollLoop(started chan<- uint) func(*LcoreCtx) {
+	return func(ctx *LcoreCtx) {
+		started <- LcoreID()
+		for !ctx.Stopped() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+// must be called with EAL initialized
+func testLcoreJobs(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	id := LcoresWorker()[0]
+	started := make(chan uint, 2)
+
+	info, err := LcoreJob(id)
+	assert(err == nil && !info.Running, info, err)
+	assert(StopLcoreJob(id) == ErrLcoreIdle)
+	assert(RestartLcoreJob(id, nil) == ErrLcoreIdle)
+	assert(StopLcoreJob(1024) == ErrLcoreInvalid)
+	_, err = LcoreJob(1024)
+	assert(err == ErrLcoreInvalid)
+
+	// stop single lcore job
+	ret := ExecOnLcoreAsync(id, make(chan error, 1), pollLoop(started))
+	assert(<-started == id)
+
+	info, err = LcoreJob(id)
+	assert(err == nil && info.Running, info, err)
+	assert(strings.Contains(info.Func, "pollLoop"), info.Func)
+	assert(!info.Since.IsZero() && info.Since.Before(time.Now()), info)
+	assert(strings.Contains(info.String(), "pollLoop"), info)
+
+	jobs := Jobs()
+	assert(len(jobs) == len(Lcores()), jobs)
+	for _, j := range jobs {
+		assert(j.Running == (j.LcoreID == id), j)
+	}
+
+	// restart the job
+	ret2 := make(chan error, 1)
+	assert(RestartLcoreJob(id, ret2) == nil)
+	assert(<-ret == nil)
+	assert(<-started == id)
+	assert(StopLcoreJob(id) == nil)
+	assert(<-ret2 == nil)
+
+	info, err = LcoreJob(id)
+	assert(err == nil && !info.Running, info, err)
+
+	// parent context cancellation
+	parent, cancel := context.WithCancel(context.Background())
+	ret = ExecOnLcoreCtxAsync(parent, id, make(chan error, 1), pollLoop(started))
+	assert(<-started == id)
+	cancel()
+	assert(<-ret == nil)
+
+	// cancelled context prevents execution
+	executed := false
+	err = ExecOnLcoreCtx(parent, id, func(*LcoreCtx) { executed = true })
+	assert(err == context.Canceled && !executed, err)
+
+	// deadline
+	var jobErr error
+	parent, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = ExecOnLcoreCtx(parent, id, func(ctx *LcoreCtx) {
+		<-ctx.Context().Done()
+		jobErr = ctx.Context().Err()
+	})
+	assert(err == nil, err)
+	assert(jobErr == context.DeadlineExceeded, jobErr)
+
+	// context of finished job is cancelled
+	var jobCtx context.Context
+	stopped := true
+	err = ExecOnMain(func(ctx *LcoreCtx) {
+		jobCtx = ctx.Context()
+		stopped = ctx.Stopped()
+	})
+	assert(err == nil && !stopped, err)
+	assert(jobCtx.Err() == context.Canceled, jobCtx.Err())
+}
@@ -0,0 +1,171 @@
+package eal
+
+/*
+#define ALLOW_EXPERIMENTAL_API
+#include <stdio.h>
+#include <string.h>
+#include <sched.h>
+
+#include <rte_config.h>
+#include <rte_lcore.h>
+
+extern int goLcoreIterCb(unsigned int, void *);
+
+static void lcore_cpuset(unsigned int lcore_id, void *dst, size_t n) {
+	rte_cpuset_t s = rte_lcore_cpuset(lcore_id);
+	memcpy(dst, &s, n < sizeof(s) ? n : sizeof(s));
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/lcore"
+	"golang.org/x/sys/unix"
+)
+
+// LcoreRole is the role of an lcore.
+type LcoreRole int
+
+// Lcore roles.
+const (
+	// RoleRTE is an EAL lcore.
+	RoleRTE LcoreRole = C.ROLE_RTE
+	// RoleOff is an unused lcore id.
+	RoleOff LcoreRole = C.ROLE_OFF
+	// RoleService is a service lcore.
+	RoleService LcoreRole = C.ROLE_SERVICE
+	// RoleNonEAL is an external thread registered with
+	// ThreadRegister.
+	RoleNonEAL LcoreRole = C.ROLE_NON_EAL
+)
+
+var roleNames = map[LcoreRole]string{
+	RoleRTE:     "RTE",
+	RoleOff:     "OFF",
+	RoleService: "SERVICE",
+	RoleNonEAL:  "NON_EAL",
+}
+
+// String implements fmt.Stringer.
+func (r LcoreRole) String() string {
+	if s, ok := roleNames[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("LcoreRole(%d)", int(r))
+}
+
+// LcoreInfo describes an lcore known to EAL.
+type LcoreInfo struct {
+	ID     uint
+	Role   LcoreRole
+	Socket uint
+	CPUSet unix.CPUSet
+}
+
+var lcoreIterCallbacks = common.NewRegistryArray()
+
+// LcoreIterate calls fn for every lcore in use, i.e. EAL, service
+// and registered non-EAL lcores, until fn returns false. Lcores can't
+// be registered or unregistered from fn.
+func LcoreIterate(fn func(id uint) bool) {
+	cb := lcoreIterCallbacks.Create(fn)
+	defer lcoreIterCallbacks.Delete(cb)
+
+	C.rte_lcore_iterate((*[0]byte)(C.goLcoreIterCb), unsafe.Pointer(&cb))
+}
+
+// GetLcoreRole returns the role of lcore id.
+func GetLcoreRole(id uint) LcoreRole {
+	return LcoreRole(C.rte_eal_lcore_role(C.uint(id)))
+}
+
+// LcoreInfos returns information about all lcores in use.
+func LcoreInfos() []LcoreInfo {
+	var infos []LcoreInfo
+	LcoreIterate(func(id uint) bool {
+		info := LcoreInfo{
+			ID:     id,
+			Role:   GetLcoreRole(id),
+			Socket: LcoreToSocket(id),
+		}
+		C.lcore_cpuset(C.uint(id), unsafe.Pointer(&info.CPUSet), C.size_t(unsafe.Sizeof(info.CPUSet)))
+		infos = append(infos, info)
+		return true
+	})
+	return infos
+}
+
+// LcoreDump writes information about all lcores in use into w.
+func LcoreDump(w io.Writer) error {
+	return common.DumpTo(w, func(fp unsafe.Pointer) {
+		C.rte_lcore_dump((*C.FILE)(fp))
+	})
+}
+
+// ThreadRegister registers the calling OS thread as a non-EAL lcore
+// and returns its lcore id. The calling goroutine must be locked to
+// its OS thread with runtime.LockOSThread and must call
+// ThreadUnregister before unlocking.
+//
+// Once registered, the thread may use lcore-dependent APIs such as
+// mempool per-lcore caches.
+//
+// Registering a non-EAL lcore disables multi-process support with
+// rte_mp_disable: it fails if a secondary process is attached, and
+// once it succeeds secondary processes can no longer attach.
+func ThreadRegister() (uint, error) {
+	if C.rte_thread_register() != 0 {
+		return 0, err()
+	}
+	return LcoreID(), nil
+}
+
+// ThreadUnregister releases the lcore id of the calling OS thread
+// registered with ThreadRegister.
+func ThreadUnregister() {
+	C.rte_thread_unregister()
+}
+
+// LcoreThread is lcore.Thread registered in EAL as a non-EAL lcore.
+type LcoreThread struct {
+	lcore.Thread
+	id uint
+}
+
+// NewLcoreThread creates lcore.Thread with lcore.NewLockedThread and
+// registers it as a non-EAL lcore.
+//
+// EAL records the CPU affinity of the thread upon registration, so
+// changing it later with SetAffinity is not reflected in LcoreInfos.
+//
+// Like ThreadRegister, it disables multi-process support.
+func NewLcoreThread(ch chan func()) (*LcoreThread, error) {
+	t := &LcoreThread{Thread: lcore.NewLockedThread(ch)}
+
+	var e error
+	t.Exec(true, func() {
+		t.id, e = ThreadRegister()
+	})
+
+	if e != nil {
+		t.Thread.Close()
+		return nil, e
+	}
+	return t, nil
+}
+
+// LcoreID returns lcore id of the thread.
+func (t *LcoreThread) LcoreID() uint {
+	return t.id
+}
+
+// Close unregisters the thread and sends a signal to finish.
+func (t *LcoreThread) Close() {
+	t.Exec(true, ThreadUnregister)
+	t.Thread.Close()
+}
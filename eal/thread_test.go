@@ -0,0 +1,64 @@
+package eal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+func findLcoreInfo(id uint) (LcoreInfo, bool) {
+	for _, info := range LcoreInfos() {
+		if info.ID == id {
+			return info, true
+		}
+	}
+	return LcoreInfo{}, false
+}
+
+// must be called with EAL initialized
+func testLcoreThreads(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	for _, id := range Lcores() {
+		assert(GetLcoreRole(id) == RoleRTE, id)
+		info, ok := findLcoreInfo(id)
+		assert(ok && info.Role == RoleRTE, info)
+		assert(info.CPUSet.Count() > 0, info)
+	}
+
+	n := 0
+	LcoreIterate(func(uint) bool {
+		n++
+		return false
+	})
+	assert(n == 1, n)
+
+	th, err := NewLcoreThread(make(chan func()))
+	assert(err == nil, err)
+
+	id := th.LcoreID()
+	for _, lid := range Lcores() {
+		assert(lid != id, lid)
+	}
+	assert(GetLcoreRole(id) == RoleNonEAL, GetLcoreRole(id))
+	assert(GetLcoreRole(id).String() == "NON_EAL")
+
+	var tid uint
+	th.Exec(true, func() { tid = LcoreID() })
+	assert(tid == id, tid)
+
+	info, ok := findLcoreInfo(id)
+	assert(ok && info.Role == RoleNonEAL, info)
+
+	buf := &bytes.Buffer{}
+	assert(LcoreDump(buf) == nil)
+	assert(buf.Len() > 0)
+
+	th.Close()
+	assert(GetLcoreRole(id) == RoleOff, GetLcoreRole(id))
+	_, ok = findLcoreInfo(id)
+	assert(!ok)
+
+	assert(LcoreRole(100).String() == "LcoreRole(100)")
+}
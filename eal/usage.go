@@ -0,0 +1,177 @@
+package eal
+
+/*
+#define ALLOW_EXPERIMENTAL_API
+#include <stdint.h>
+
+#include <rte_config.h>
+#include <rte_version.h>
+#include <rte_cycles.h>
+#include <rte_lcore.h>
+
+extern int goLcoreUsage(unsigned int, uint64_t *, uint64_t *);
+
+// returns 0 if usage callback is not supported
+#if RTE_VERSION >= RTE_VERSION_NUM(23, 3, 0, 0)
+static int lcore_usage_cb(unsigned int lcore_id, struct rte_lcore_usage *usage) {
+	return goLcoreUsage(lcore_id, &usage->total_cycles, &usage->busy_cycles);
+}
+
+static int lcore_usage_register(void) {
+	rte_lcore_register_usage_cb(lcore_usage_cb);
+	return 1;
+}
+#else
+static int lcore_usage_register(void) {
+	return 0;
+}
+#endif
+*/
+import "C"
+
+import (
+	"sync/atomic"
+	"syscall"
+)
+
+// LcoreUsage is the accumulated usage of an lcore in TSC cycles.
+type LcoreUsage struct {
+	// TotalCycles is the number of cycles spent in accounted
+	// polls.
+	TotalCycles uint64
+
+	// BusyCycles is the number of cycles spent in polls which did
+	// some work, e.g. received packets.
+	BusyCycles uint64
+}
+
+// BusyRatio returns the fraction of accounted cycles the lcore was
+// busy, from 0 to 1. Zero is returned if nothing was accounted.
+func (u LcoreUsage) BusyRatio() float64 {
+	if u.TotalCycles == 0 {
+		return 0
+	}
+	return float64(u.BusyCycles) / float64(u.TotalCycles)
+}
+
+type usageCounter struct {
+	total atomic.Uint64
+	busy  atomic.Uint64
+	_     [C.RTE_CACHE_LINE_SIZE - 16]byte
+}
+
+var lcoreUsage [C.RTE_MAX_LCORE]usageCounter
+
+func (c *usageCounter) load() LcoreUsage {
+	// busy is read first so it never exceeds total
+	busy := c.busy.Load()
+	return LcoreUsage{TotalCycles: c.total.Load(), BusyCycles: busy}
+}
+
+// UsageAdd accounts total cycles spent by lcore lcoreID, of which
+// busy cycles were spent doing some work. Only one thread should
+// account cycles for an lcore. Invalid lcore ids are ignored.
+func UsageAdd(lcoreID uint, total, busy uint64) {
+	if lcoreID >= C.RTE_MAX_LCORE {
+		return
+	}
+	c := &lcoreUsage[lcoreID]
+	c.total.Add(total)
+	c.busy.Add(busy)
+}
+
+// UsageReset clears accounted usage of lcore lcoreID.
+func UsageReset(lcoreID uint) {
+	if lcoreID >= C.RTE_MAX_LCORE {
+		return
+	}
+	c := &lcoreUsage[lcoreID]
+	c.busy.Store(0)
+	c.total.Store(0)
+}
+
+// GetLcoreUsage returns accounted usage of lcore lcoreID. false is
+// returned if nothing was accounted for the lcore.
+func GetLcoreUsage(lcoreID uint) (LcoreUsage, bool) {
+	if lcoreID >= C.RTE_MAX_LCORE {
+		return LcoreUsage{}, false
+	}
+	u := lcoreUsage[lcoreID].load()
+	return u, u.TotalCycles != 0
+}
+
+// LcoreUsages returns accounted usage of all lcores which have
+// anything accounted.
+func LcoreUsages() map[uint]LcoreUsage {
+	m := map[uint]LcoreUsage{}
+	for id := range lcoreUsage {
+		if u, ok := GetLcoreUsage(uint(id)); ok {
+			m[uint(id)] = u
+		}
+	}
+	return m
+}
+
+// UsageRegister registers accounted usage in EAL so it is reported
+// by rte_lcore_dump and /eal/lcore/usage telemetry command. Only one
+// usage callback may be registered in EAL so the call replaces any
+// previously registered one. It requires DPDK 23.03 or later,
+// otherwise ENOTSUP is returned.
+//
+// Other processes such as dpdk-exporter retrieve the usage through
+// the telemetry socket of the application.
+func UsageRegister() error {
+	if C.lcore_usage_register() == 0 {
+		return syscall.ENOTSUP
+	}
+	return nil
+}
+
+// UsageMeter accounts cycles of a polling loop running on an lcore.
+// It is not safe for concurrent use.
+//
+// Example:
+//
+//	meter := eal.NewUsageMeter()
+//	for !ctx.Stopped() {
+//		n := rxq.RxBurst(pkts)
+//		...
+//		meter.Poll(n != 0)
+//	}
+type UsageMeter struct {
+	lcoreID uint
+	last    uint64
+}
+
+// NewUsageMeter creates UsageMeter accounting cycles to the calling
+// lcore. This function must be called only in EAL thread or
+// registered non-EAL thread.
+func NewUsageMeter() *UsageMeter {
+	return NewUsageMeterOn(LcoreID())
+}
+
+// NewUsageMeterOn creates UsageMeter accounting cycles to lcore
+// lcoreID.
+func NewUsageMeterOn(lcoreID uint) *UsageMeter {
+	return &UsageMeter{lcoreID, uint64(C.rte_rdtsc())}
+}
+
+// LcoreID returns lcore id the meter accounts cycles to.
+func (m *UsageMeter) LcoreID() uint {
+	return m.lcoreID
+}
+
+// Poll accounts cycles elapsed since previous call to Poll or meter
+// creation. They are accounted as busy if busy is true, i.e. the poll
+// did some work.
+func (m *UsageMeter) Poll(busy bool) {
+	now := uint64(C.rte_rdtsc())
+	d := now - m.last
+	m.last = now
+
+	if busy {
+		UsageAdd(m.lcoreID, d, d)
+	} else {
+		UsageAdd(m.lcoreID, d, 0)
+	}
+}
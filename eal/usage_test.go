@@ -0,0 +1,76 @@
+package eal
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+func TestLcoreUsage(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	assert(LcoreUsage{}.BusyRatio() == 0)
+	assert(LcoreUsage{TotalCycles: 4, BusyCycles: 1}.BusyRatio() == 0.25)
+	assert(LcoreUsage{TotalCycles: 4, BusyCycles: 4}.BusyRatio() == 1)
+
+	const id = 3
+	UsageReset(id)
+	_, ok := GetLcoreUsage(id)
+	assert(!ok)
+
+	UsageAdd(id, 100, 30)
+	UsageAdd(id, 100, 0)
+	u, ok := GetLcoreUsage(id)
+	assert(ok)
+	assert(u.TotalCycles == 200 && u.BusyCycles == 30, u)
+
+	_, ok = LcoreUsages()[id]
+	assert(ok)
+
+	UsageReset(id)
+	_, ok = GetLcoreUsage(id)
+	assert(!ok)
+
+	// invalid lcore ids are ignored
+	UsageAdd(1<<20, 1, 1)
+	_, ok = GetLcoreUsage(1 << 20)
+	assert(!ok)
+}
+
+// must be called with EAL initialized
+func testLcoreUsage(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	err := UsageRegister()
+	assert(err == nil || err == syscall.ENOTSUP, err)
+
+	// telemetry socket is created in runtime dir
+	assert(RuntimeDir() != "")
+
+	id := LcoresWorker()[0]
+	UsageReset(id)
+
+	var meterID uint
+	assert(ExecOnLcore(id, func(ctx *LcoreCtx) {
+		meter := NewUsageMeter()
+		meterID = meter.LcoreID()
+		for i := 0; i < 1000; i++ {
+			meter.Poll(i%2 == 0)
+		}
+	}) == nil)
+	assert(meterID == id, meterID)
+
+	u, ok := GetLcoreUsage(id)
+	assert(ok)
+	assert(u.TotalCycles > 0 && u.BusyCycles > 0, u)
+	assert(u.BusyCycles < u.TotalCycles, u)
+	assert(u.BusyRatio() > 0 && u.BusyRatio() < 1, u.BusyRatio())
+
+	buf := &bytes.Buffer{}
+	assert(LcoreDump(buf) == nil)
+	assert(buf.Len() > 0)
+
+	UsageReset(id)
+}
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/tianyuansun/go-dpdk/eal"
+	"github.com/tianyuansun/go-dpdk/mbuf"
+	"github.com/tianyuansun/go-dpdk/mempool"
 )
 
 func TestMACAddr(t *testing.T) {
@@ -174,3 +176,65 @@ func TestLSC(t *testing.T) {
 
 	RegisterTelemetryLSC("/ethdev/lsc")
 }
+
+func TestTxOffload(t *testing.T) {
+	eal.InitOnceSafe("test", 4)
+
+	err := eal.ExecOnMain(func(*eal.LcoreCtx) {
+		pid := Port(0)
+
+		mp, err := mempool.CreateMbufPool("test_tx_offload", 1024, 2048)
+		assert(t, err == nil, err)
+		defer mp.Free()
+
+		err = pid.DevConfigure(1, 1)
+		assert(t, err == nil, err)
+		err = pid.RxqSetup(0, 128, mp)
+		assert(t, err == nil, err)
+		err = pid.TxqSetup(0, 128)
+		assert(t, err == nil, err)
+		err = pid.Start()
+		assert(t, err == nil, err)
+		defer pid.Stop()
+
+		pkts := make([]*mbuf.Mbuf, 4)
+		err = mbuf.PktMbufAllocBulk(mp, pkts)
+		assert(t, err == nil, err)
+
+		for _, m := range pkts {
+			// Ethernet + IPv4 + TCP headers
+			assert(t, m.PktMbufAppend(make([]byte, 54)) == nil)
+			m.SetOlFlags(mbuf.TxIPv4 | mbuf.TxIPCksum | mbuf.TxTCPCksum)
+			m.SetL2Len(14)
+			m.SetL3Len(20)
+			m.SetL4Len(20)
+			assert(t, m.OlFlags().Has(mbuf.TxIPCksum|mbuf.TxTCPCksum))
+		}
+
+		// net_null doesn't prepare packets so all of them are valid
+		n, err := pid.TxPrepare(0, pkts)
+		assert(t, err == nil, err)
+		assert(t, n == uint16(len(pkts)), n)
+
+		// net_null frees all transmitted packets
+		n = pid.TxBurst(0, pkts)
+		assert(t, n == uint16(len(pkts)), n)
+		assert(t, mp.InUseCount() == 0, mp.InUseCount())
+	})
+	assert(t, err == nil, err)
+}
+
+func TestPtypes(t *testing.T) {
+	eal.InitOnceSafe("test", 4)
+
+	pid := Port(0)
+
+	ptypes, err := pid.SupportedPtypes(mbuf.PtypeAllMask)
+	assert(t, err == nil || err == syscall.ENOTSUP, err)
+	for _, p := range ptypes {
+		assert(t, p&^mbuf.PtypeAllMask == 0, p)
+	}
+
+	_, err = pid.SetPtypes(mbuf.PtypeUnknown)
+	assert(t, err == nil || err == syscall.ENOTSUP, err)
+}
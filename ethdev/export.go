@@ -0,0 +1,23 @@
+package ethdev
+
+/*
+#include <rte_config.h>
+#include <rte_ethdev.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+//export goEthEventCb
+func goEthEventCb(port C.uint16_t, event C.enum_rte_eth_event_type, arg, ret unsafe.Pointer) C.int {
+	ch := portEventChans.Read(*(*common.ObjectID)(arg)).(chan<- PortEvent)
+	select {
+	case ch <- PortEvent{Port(port), EventType(event)}:
+	default:
+	}
+	return 0
+}
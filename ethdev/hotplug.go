@@ -0,0 +1,152 @@
+package ethdev
+
+/*
+#include <stdlib.h>
+
+#include <rte_config.h>
+#include <rte_ethdev.h>
+#include <rte_dev.h>
+
+extern int goEthEventCb(uint16_t port, enum rte_eth_event_type event, void *arg, void *ret);
+
+static int eth_event_callback_register(enum rte_eth_event_type event, void *arg) {
+	return rte_eth_dev_callback_register(RTE_ETH_ALL, event, goEthEventCb, arg);
+}
+
+static int eth_event_callback_unregister(enum rte_eth_event_type event, void *arg) {
+	return rte_eth_dev_callback_unregister(RTE_ETH_ALL, event, goEthEventCb, arg);
+}
+
+static int eth_iterate_matching(const char *devargs, uint16_t *ports, int n) {
+	struct rte_dev_iterator it;
+	uint16_t pid;
+	int i = 0;
+
+	RTE_ETH_FOREACH_MATCHING_DEV(pid, devargs, &it) {
+		if (i == n) {
+			rte_eth_iterator_cleanup(&it);
+			break;
+		}
+		ports[i++] = pid;
+	}
+	return i;
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/dev"
+)
+
+// EventType is an Ethernet device event type.
+type EventType uint32
+
+// Ethernet device event types.
+const (
+	// EventNew is reported when a port is probed and ready to use.
+	EventNew EventType = C.RTE_ETH_EVENT_NEW
+	// EventDestroy is reported when a port is released.
+	EventDestroy EventType = C.RTE_ETH_EVENT_DESTROY
+	// EventIntrRmv is reported when a device is removed while in
+	// use.
+	EventIntrRmv EventType = C.RTE_ETH_EVENT_INTR_RMV
+)
+
+// PortEvent is an Ethernet device event.
+type PortEvent struct {
+	Port Port
+	Type EventType
+}
+
+// EventCallback is a registered port event channel.
+type EventCallback struct {
+	event EventType
+	arg   *common.ObjectID
+}
+
+var (
+	portEventChans = common.NewRegistryArray()
+)
+
+// RegisterEventChan delivers events of type event for all ports into
+// ch. The events are sent without blocking so they are dropped if ch
+// is full.
+func RegisterEventChan(event EventType, ch chan<- PortEvent) (*EventCallback, error) {
+	obj := portEventChans.Create(ch)
+	cb := &EventCallback{event: event}
+	cb.arg = (*common.ObjectID)(C.malloc(C.size_t(unsafe.Sizeof(obj))))
+	*cb.arg = obj
+
+	e := errget(C.eth_event_callback_register(C.enum_rte_eth_event_type(event), unsafe.Pointer(cb.arg)))
+	if e != nil {
+		cb.free()
+		return nil, e
+	}
+	return cb, nil
+}
+
+func (cb *EventCallback) free() {
+	portEventChans.Delete(*cb.arg)
+	C.free(unsafe.Pointer(cb.arg))
+	cb.arg = nil
+}
+
+// Unregister stops delivery of events into the channel. The channel
+// is not closed. Repeated calls have no effect.
+func (cb *EventCallback) Unregister() error {
+	if cb.arg == nil {
+		return nil
+	}
+
+	e := errget(C.eth_event_callback_unregister(C.enum_rte_eth_event_type(cb.event), unsafe.Pointer(cb.arg)))
+	if e == nil {
+		cb.free()
+	}
+	return e
+}
+
+// Attach probes the device specified by devargs and returns its
+// ports, e.g. Attach("net_null1") or Attach("0000:02:00.0"). The
+// ports appear in ValidPorts and should be configured as usual.
+func Attach(devargs string) ([]Port, error) {
+	if e := dev.Probe(devargs); e != nil {
+		return nil, e
+	}
+
+	cargs := C.CString(devargs)
+	defer C.free(unsafe.Pointer(cargs))
+
+	var ports [C.RTE_MAX_ETHPORTS]Port
+	n := C.eth_iterate_matching(cargs, (*C.uint16_t)(unsafe.Pointer(&ports[0])), C.RTE_MAX_ETHPORTS)
+	return append([]Port(nil), ports[:n]...), nil
+}
+
+// Device returns the generic device of the port.
+func (pid Port) Device() (*dev.Device, error) {
+	var info DevInfo
+	if e := pid.InfoGet(&info); e != nil {
+		return nil, e
+	}
+	return (*dev.Device)(unsafe.Pointer(info.device)), nil
+}
+
+// Detach stops and closes the port and removes its device. Other
+// ports of the same device are closed as well.
+func (pid Port) Detach() error {
+	d, e := pid.Device()
+	if e != nil {
+		return e
+	}
+
+	for _, p := range ValidPorts() {
+		if pd, e := p.Device(); e == nil && pd == d {
+			p.Stop()
+			p.Close()
+		}
+	}
+
+	return d.Remove()
+}
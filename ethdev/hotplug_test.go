@@ -0,0 +1,86 @@
+package ethdev
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tianyuansun/go-dpdk/eal"
+)
+
+func hasPort(ports []Port, pid Port) bool {
+	for _, p := range ports {
+		if p == pid {
+			return true
+		}
+	}
+	return false
+}
+
+func waitEvent(ch <-chan PortEvent) (PortEvent, bool) {
+	select {
+	case ev := <-ch:
+		return ev, true
+	case <-time.After(time.Second):
+		return PortEvent{}, false
+	}
+}
+
+func TestHotplug(t *testing.T) {
+	eal.InitOnceSafe("test", 4)
+
+	chNew := make(chan PortEvent, 4)
+	cbNew, err := RegisterEventChan(EventNew, chNew)
+	assert(t, err == nil, err)
+	defer cbNew.Unregister()
+
+	chDestroy := make(chan PortEvent, 4)
+	cbDestroy, err := RegisterEventChan(EventDestroy, chDestroy)
+	assert(t, err == nil, err)
+	defer cbDestroy.Unregister()
+
+	n := CountAvail()
+	ports, err := Attach("net_null_hotplug0")
+	assert(t, err == nil, err)
+	assert(t, len(ports) == 1, ports)
+
+	pid := ports[0]
+	assert(t, pid.IsValid())
+	assert(t, CountAvail() == n+1)
+	assert(t, hasPort(ValidPorts(), pid))
+
+	name, err := pid.Name()
+	assert(t, err == nil && name == "net_null_hotplug0", name, err)
+
+	d, err := pid.Device()
+	assert(t, err == nil, err)
+	assert(t, d.Name() == "net_null_hotplug0", d.Name())
+
+	ev, ok := waitEvent(chNew)
+	assert(t, ok && ev == PortEvent{pid, EventNew}, ev)
+
+	// attaching twice is an error
+	_, err = Attach("net_null_hotplug0")
+	assert(t, err != nil)
+
+	// the new port is usable
+	err = pid.DevConfigure(1, 1)
+	assert(t, err == nil, err)
+
+	assert(t, pid.Detach() == nil)
+	assert(t, !pid.IsValid())
+	assert(t, CountAvail() == n)
+	assert(t, !hasPort(ValidPorts(), pid))
+
+	ev, ok = waitEvent(chDestroy)
+	assert(t, ok && ev == PortEvent{pid, EventDestroy}, ev)
+
+	assert(t, cbNew.Unregister() == nil)
+	_, err = Attach("net_null_hotplug1")
+	assert(t, err == nil, err)
+	_, ok = waitEvent(chNew)
+	assert(t, !ok)
+
+	pid, err = GetPortByName("net_null_hotplug1")
+	assert(t, err == nil, err)
+	assert(t, pid.Detach() == nil)
+}
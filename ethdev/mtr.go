@@ -8,6 +8,7 @@ package ethdev
 
 #include <rte_config.h>
 #include <rte_version.h>
+#include <rte_log.h>
 #include <rte_mtr.h>
 
 
@@ -25,11 +26,11 @@ int query_mtr_stats(uint16_t port, uint32_t mtr_id, struct MtrStats *stats, stru
 	memset(&mtr_stats, 0, sizeof(struct rte_mtr_stats));
 	ret = rte_mtr_stats_read(port, mtr_id, &mtr_stats, &stats_mask, 0, error);
 	if (ret != 0 ) {
-	    fprintf(stderr, "failed to query_mtr_stats, mtr_id is %d, error is %s\n", mtr_id, error->message);
+	    RTE_LOG(ERR, USER1, "failed to query_mtr_stats, mtr_id is %d, error is %s\n", mtr_id, error->message);
 		return ret;
 	}
-	fprintf(stderr, "mtr stats: g pkts %d y pkts %d\n", mtr_stats.n_pkts[0], mtr_stats.n_pkts[1]);
-	fprintf(stderr, "mtr stats: g bytes %d y bytes %d\n", mtr_stats.n_bytes[0], mtr_stats.n_bytes[1]);
+	RTE_LOG(DEBUG, USER1, "mtr stats: g pkts %d y pkts %d\n", mtr_stats.n_pkts[0], mtr_stats.n_pkts[1]);
+	RTE_LOG(DEBUG, USER1, "mtr stats: g bytes %d y bytes %d\n", mtr_stats.n_bytes[0], mtr_stats.n_bytes[1]);
 	stats->Pkts = mtr_stats.n_pkts[0] + mtr_stats.n_pkts[1];
 	stats->Bytes = mtr_stats.n_bytes[0] + mtr_stats.n_bytes[1];
 	stats->DropPkts = mtr_stats.n_pkts_dropped;
@@ -52,7 +53,7 @@ static int add_srtcm_mtr_profile(uint16_t port, uint32_t profile_id, uint64_t ci
 
 	ret = rte_mtr_meter_profile_add(port, profile_id, &profile, &error);
 	if (ret != 0) {
-		fprintf(stderr, "failed to add_srtcm_mtr_profile, profile_id is %d, error is %s\n", profile_id, error.message);
+		RTE_LOG(ERR, USER1, "failed to add_srtcm_mtr_profile, profile_id is %d, error is %s\n", profile_id, error.message);
 	}
 	return ret;
 }
@@ -76,7 +77,7 @@ static int add_mtr_policy(uint16_t port, uint32_t policy_id) {
     };
 	ret = rte_mtr_meter_policy_add(port, policy_id, &policy, &error);
 	if (ret != 0) {
-		fprintf(stderr, "failed to add meter policy, policy id is %d, error is %s\n", policy_id, error.message);
+		RTE_LOG(ERR, USER1, "failed to add meter policy, policy id is %d, error is %s\n", policy_id, error.message);
 	}
 	return ret;
 }
@@ -94,7 +95,7 @@ static int add_mtr(uint16_t port, uint32_t mtr_id, uint32_t profile_id, uint32_t
 	params.stats_mask = 0xffff;
 	ret = rte_mtr_create(port, mtr_id, &params, 1, &error);
 	if (ret != 0) {
-		fprintf(stderr, "failed to add mtr, mtr id is %d, error is %s\n", mtr_id, error.message);
+		RTE_LOG(ERR, USER1, "failed to add mtr, mtr id is %d, error is %s\n", mtr_id, error.message);
 	}
 	return ret;
 }
@@ -0,0 +1,91 @@
+package ethdev
+
+/*
+#include <errno.h>
+
+#include <rte_config.h>
+#include <rte_ethdev.h>
+*/
+import "C"
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/mbuf"
+)
+
+// ptypeErr is errget which returns syscall.ENOTSUP as is so callers
+// may test for unsupported drivers.
+func ptypeErr(n C.int) error {
+	if n == -C.ENOTSUP {
+		return syscall.ENOTSUP
+	}
+	return errget(n)
+}
+
+func ptypes(p []mbuf.PacketType) *C.uint32_t {
+	if len(p) == 0 {
+		return nil
+	}
+	return (*C.uint32_t)(unsafe.Pointer(&p[0]))
+}
+
+// SupportedPtypes retrieves the supported packet type identification
+// of an Ethernet device restricted by mask, e.g.
+// mbuf.PtypeL3Mask|mbuf.PtypeL4Mask.
+//
+// Packet types which the driver recognizes in the received packets
+// are reported in packet type of mbufs so the application may skip
+// parsing of respective headers.
+//
+// Note: Better to invoke this API after the device is already
+// started or RX burst function is decided, to obtain correct
+// supported ptypes. syscall.ENOTSUP is returned if the driver doesn't
+// support packet type identification.
+func (pid Port) SupportedPtypes(mask mbuf.PacketType) ([]mbuf.PacketType, error) {
+	n := C.rte_eth_dev_get_supported_ptypes(C.uint16_t(pid), C.uint32_t(mask), nil, 0)
+	if n <= 0 {
+		return nil, ptypeErr(n)
+	}
+
+	p := make([]mbuf.PacketType, n)
+	n = C.rte_eth_dev_get_supported_ptypes(C.uint16_t(pid), C.uint32_t(mask), ptypes(p), n)
+	if n < 0 {
+		return nil, ptypeErr(n)
+	}
+	return p[:n], nil
+}
+
+// SetPtypes informs Ethernet device about reduced range of packet
+// types to handle. Application can use this function to set only
+// specific ptypes that it's interested in. This information can be
+// used by the PMD to optimize Rx path.
+//
+// The function accepts a mask of layers the application is interested
+// in, e.g. mbuf.PtypeL3Mask|mbuf.PtypeL4Mask. Specifying mask equal to
+// mbuf.PtypeUnknown disables packet type parsing by the driver.
+//
+// Returns the list of packet types which were actually set.
+// syscall.ENOTSUP is returned if the driver doesn't support packet
+// type identification.
+func (pid Port) SetPtypes(mask mbuf.PacketType) ([]mbuf.PacketType, error) {
+	n := C.rte_eth_dev_get_supported_ptypes(C.uint16_t(pid), C.uint32_t(mask), nil, 0)
+	if n < 0 {
+		return nil, ptypeErr(n)
+	}
+
+	// reserve space for RTE_PTYPE_UNKNOWN terminator
+	p := make([]mbuf.PacketType, n+1)
+	rc := C.rte_eth_dev_set_ptypes(C.uint16_t(pid), C.uint32_t(mask), ptypes(p), C.uint(len(p)))
+	if rc < 0 {
+		return nil, ptypeErr(rc)
+	}
+
+	for i := range p {
+		if p[i] == mbuf.PtypeUnknown {
+			return p[:i], nil
+		}
+	}
+	return p, nil
+}
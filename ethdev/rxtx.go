@@ -50,12 +50,45 @@ func (pid Port) RxBurst(qid uint16, pkts []*mbuf.Mbuf, burst uint16) uint16 {
 }
 
 // TxBurst sends packets over port pid and queue qid. Returns number of
-// packets sent from pkts.
+// packets sent from pkts. Sent packets are freed by the driver and
+// forgotten by mbuf leak tracker.
 func (pid Port) TxBurst(qid uint16, pkts []*mbuf.Mbuf) uint16 {
+	if !mbuf.TrackEnabled {
+		return pid.txBurst(qid, pkts)
+	}
+	// sent packets may be freed by the time the call returns
+	taken := mbuf.Handover(pkts)
+	n := pid.txBurst(qid, pkts)
+	taken(int(n))
+	return n
+}
+
+func (pid Port) txBurst(qid uint16, pkts []*mbuf.Mbuf) uint16 {
 	return uint16(C.rte_eth_tx_burst(C.uint16_t(pid), C.uint16_t(qid),
 		(**C.struct_rte_mbuf)(unsafe.Pointer(&pkts[0])), C.uint16_t(len(pkts))))
 }
 
+// TxPrepare processes a burst of output packets on a transmit queue
+// of an Ethernet device before they are sent with TxBurst. It checks
+// and prepares packets for the offloads requested in their ol_flags
+// and header lengths, e.g. fills pseudo-header checksums required by
+// the hardware.
+//
+// Returns number of packets ready to be sent. If it is less than
+// len(pkts) then the packet at the returned index is invalid and
+// rte_errno is set, i.e. an error is returned as well.
+func (pid Port) TxPrepare(qid uint16, pkts []*mbuf.Mbuf) (uint16, error) {
+	if len(pkts) == 0 {
+		return 0, nil
+	}
+	n := C.rte_eth_tx_prepare(C.uint16_t(pid), C.uint16_t(qid),
+		(**C.struct_rte_mbuf)(unsafe.Pointer(&pkts[0])), C.uint16_t(len(pkts)))
+	if int(n) < len(pkts) {
+		return uint16(n), errget()
+	}
+	return uint16(n), nil
+}
+
 // TxBufferFlush Send any packets queued up for transmission on a port
 // and HW queue.
 //
@@ -81,7 +114,13 @@ func (pid Port) TxBufferFlush(qid uint16, buf *TxBuffer) uint16 {
 // are just freed back to the owning mempool. The function returns the
 // number of packets actually sent i.e. 0 if no buffer flush occurred,
 // otherwise the number of packets successfully flushed
+//
+// Buffered packet is forgotten by mbuf leak tracker before the call
+// since it is either sent or freed by the callback.
 func (pid Port) TxBuffer(qid uint16, buf *TxBuffer, m *mbuf.Mbuf) uint16 {
+	if mbuf.TrackEnabled {
+		mbuf.Untrack(m)
+	}
 	return uint16(C.rte_eth_tx_buffer(C.uint16_t(pid), C.uint16_t(qid),
 		(*C.struct_rte_eth_dev_tx_buffer)(unsafe.Pointer(buf)),
 		(*C.struct_rte_mbuf)(unsafe.Pointer(m))))
@@ -0,0 +1,6 @@
+package log
+
+/*
+#cgo pkg-config: libdpdk
+*/
+import "C"
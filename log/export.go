@@ -0,0 +1,25 @@
+package log
+
+/*
+#include <stdint.h>
+#include <stddef.h>
+*/
+import "C"
+
+import (
+	"strings"
+)
+
+//export goLogWrite
+func goLogWrite(buf *C.char, size C.size_t, level C.uint32_t, logtype C.int) {
+	h, _ := handler.Load().(Handler)
+	if h == nil {
+		return
+	}
+
+	h(&Record{
+		Level: Level(level),
+		Type:  int(logtype),
+		Msg:   strings.TrimRight(C.GoStringN(buf, C.int(size)), "\n"),
+	})
+}
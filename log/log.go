@@ -0,0 +1,300 @@
+/*
+Package log wraps RTE log library.
+
+It allows to route messages logged by DPDK into a Go Handler, e.g.
+a log.Logger or a log/slog handler (Go 1.21 or later), and to
+control per-component log levels. Go components may register their
+own log types and log through DPDK so that their messages obey the
+same level settings.
+
+Handler is called synchronously in the thread logging a message, so
+tests may capture DPDK output deterministically with Capture.
+*/
+package log
+
+/*
+#define _GNU_SOURCE
+#include <stdio.h>
+#include <stdlib.h>
+#include <stdint.h>
+#include <errno.h>
+
+#include <rte_config.h>
+#include <rte_log.h>
+
+extern void goLogWrite(char *buf, size_t size, uint32_t level, int logtype);
+
+static ssize_t log_write(void *cookie, const char *buf, size_t size) {
+	goLogWrite((char *)buf, size, rte_log_cur_msg_loglevel(),
+		rte_log_cur_msg_logtype());
+	return size;
+}
+
+static FILE *log_stream;
+
+// rte_vlog flushes the stream after each message so every write
+// carries single message
+static int log_stream_open(void) {
+	if (log_stream == NULL) {
+		cookie_io_functions_t fns = { .write = log_write };
+		log_stream = fopencookie(NULL, "w", fns);
+		if (log_stream == NULL)
+			return -errno;
+	}
+	return rte_openlog_stream(log_stream);
+}
+
+static int log_msg(uint32_t level, uint32_t logtype, const char *msg) {
+	return rte_log(level, logtype, "%s\n", msg);
+}
+*/
+import "C"
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	stdlog "log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// Level is a log level.
+type Level uint32
+
+// Log levels.
+const (
+	Emerg   Level = C.RTE_LOG_EMERG   // System is unusable.
+	Alert   Level = C.RTE_LOG_ALERT   // Action must be taken immediately.
+	Crit    Level = C.RTE_LOG_CRIT    // Critical conditions.
+	Err     Level = C.RTE_LOG_ERR     // Error conditions.
+	Warning Level = C.RTE_LOG_WARNING // Warning conditions.
+	Notice  Level = C.RTE_LOG_NOTICE  // Normal but significant condition.
+	Info    Level = C.RTE_LOG_INFO    // Informational.
+	Debug   Level = C.RTE_LOG_DEBUG   // Debug-level messages.
+)
+
+var levelNames = map[Level]string{
+	Emerg:   "EMERG",
+	Alert:   "ALERT",
+	Crit:    "CRIT",
+	Err:     "ERR",
+	Warning: "WARNING",
+	Notice:  "NOTICE",
+	Info:    "INFO",
+	Debug:   "DEBUG",
+}
+
+// String implements fmt.Stringer.
+func (l Level) String() string {
+	if s, ok := levelNames[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("Level(%d)", uint32(l))
+}
+
+// Static log types.
+const (
+	TypeEAL  = C.RTE_LOGTYPE_EAL
+	TypeUser = C.RTE_LOGTYPE_USER1
+)
+
+// Record is a single log message.
+type Record struct {
+	Level Level
+	Type  int
+	Msg   string // Message without trailing newline.
+}
+
+// Handler handles log messages. It is called in the thread which
+// logged the message, possibly concurrently. It must not call DPDK
+// logging functions.
+type Handler func(*Record)
+
+var handler atomic.Value
+
+func err(n ...interface{}) error {
+	if len(n) == 0 {
+		return common.RteErrno()
+	}
+
+	return common.IntToErr(n[0])
+}
+
+// SetHandler routes DPDK log messages into h. If h is nil, default
+// DPDK log stream is restored. It may be called before EAL
+// initialization to handle EAL messages.
+//
+// Returns previous handler.
+func SetHandler(h Handler) (Handler, error) {
+	prev, _ := handler.Load().(Handler)
+	handler.Store(h)
+
+	if h == nil {
+		return prev, err(C.rte_openlog_stream(nil))
+	}
+	return prev, err(C.log_stream_open())
+}
+
+// NewLoggerHandler returns Handler which prints records into l.
+func NewLoggerHandler(l *stdlog.Logger) Handler {
+	return func(r *Record) {
+		l.Printf("%s: %s", r.Level, r.Msg)
+	}
+}
+
+// Recorder is a Handler which stores records in memory.
+type Recorder struct {
+	mu   sync.Mutex
+	recs []Record
+}
+
+// Handle records r.
+func (rec *Recorder) Handle(r *Record) {
+	rec.mu.Lock()
+	rec.recs = append(rec.recs, *r)
+	rec.mu.Unlock()
+}
+
+// Records returns the recorded records.
+func (rec *Recorder) Records() []Record {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]Record(nil), rec.recs...)
+}
+
+// Capture runs fn and returns all records logged meanwhile. Previous
+// handler is restored afterwards. It's mostly intended to use in
+// tests.
+func Capture(fn func()) ([]Record, error) {
+	rec := &Recorder{}
+	prev, e := SetHandler(rec.Handle)
+	if e != nil {
+		return nil, e
+	}
+	defer SetHandler(prev)
+
+	fn()
+	return rec.Records(), nil
+}
+
+// Log logs msg with specified level and type through DPDK. The
+// message is dropped if its level is above the type level or global
+// level.
+func Log(level Level, logtype int, msg string) error {
+	cmsg := C.CString(msg)
+	defer C.free(unsafe.Pointer(cmsg))
+	if n := C.log_msg(C.uint32_t(level), C.uint32_t(logtype), cmsg); n < 0 {
+		return err(n)
+	}
+	return nil
+}
+
+// Logf is Log with message formatted by fmt.Sprintf.
+func Logf(level Level, logtype int, format string, a ...interface{}) error {
+	return Log(level, logtype, fmt.Sprintf(format, a...))
+}
+
+// CanLog tells if a message with specified level and type would be
+// logged.
+func CanLog(logtype int, level Level) bool {
+	return bool(C.rte_log_can_log(C.uint32_t(logtype), C.uint32_t(level)))
+}
+
+// SetGlobalLevel sets global log level. Messages above this level
+// are dropped regardless of type level.
+func SetGlobalLevel(level Level) {
+	C.rte_log_set_global_level(C.uint32_t(level))
+}
+
+// GlobalLevel returns global log level.
+func GlobalLevel() Level {
+	return Level(C.rte_log_get_global_level())
+}
+
+// SetLevel sets log level of specified type.
+func SetLevel(logtype int, level Level) error {
+	return err(C.rte_log_set_level(C.uint32_t(logtype), C.uint32_t(level)))
+}
+
+// GetLevel returns log level of specified type.
+func GetLevel(logtype int) (Level, error) {
+	n, e := common.IntOrErr(C.rte_log_get_level(C.uint32_t(logtype)))
+	return Level(n), e
+}
+
+// SetLevelPattern sets log level of all registered types with names
+// matching shell pattern, e.g. "pmd.net.*". Types registered later are
+// not affected, unlike patterns given with --log-level EAL option.
+func SetLevelPattern(pattern string, level Level) error {
+	cpattern := C.CString(pattern)
+	defer C.free(unsafe.Pointer(cpattern))
+	return err(C.rte_log_set_level_pattern(cpattern, C.uint32_t(level)))
+}
+
+// SetLevelRegexp sets log level of all registered types with names
+// matching regular expression. Types registered later are not
+// affected, unlike patterns given with --log-level EAL option.
+func SetLevelRegexp(regex string, level Level) error {
+	cregex := C.CString(regex)
+	defer C.free(unsafe.Pointer(cregex))
+	return err(C.rte_log_set_level_regexp(cregex, C.uint32_t(level)))
+}
+
+// Register registers dynamic log type name and returns its id. If the
+// name is already registered, its id is returned.
+func Register(name string) (int, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return common.IntOrErr(C.rte_log_register(cname))
+}
+
+// RegisterLevel is Register which sets the level of newly registered
+// type to level unless it is overridden by patterns specified with
+// --log-level EAL option.
+func RegisterLevel(name string, level Level) (int, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return common.IntOrErr(C.rte_log_register_type_and_pick_level(cname, C.uint32_t(level)))
+}
+
+// Dump writes all log types and their levels into w.
+func Dump(w io.Writer) error {
+	return common.DumpTo(w, func(fp unsafe.Pointer) {
+		C.rte_log_dump((*C.FILE)(fp))
+	})
+}
+
+// Types returns names of all log types indexed by type id. It parses
+// the output of Dump. Error is returned if the output is not empty but
+// no types can be parsed from it, e.g. if its format has changed.
+func Types() (map[int]string, error) {
+	buf := &bytes.Buffer{}
+	if e := Dump(buf); e != nil {
+		return nil, e
+	}
+	dumped := buf.Len() != 0
+
+	types := map[int]string{}
+	s := bufio.NewScanner(buf)
+	for s.Scan() {
+		var id int
+		var name string
+		// id 0: lib.eal, level is info
+		if n, _ := fmt.Sscanf(strings.TrimSpace(s.Text()), "id %d: %s", &id, &name); n == 2 {
+			types[id] = strings.TrimSuffix(name, ",")
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if dumped && len(types) == 0 {
+		return nil, fmt.Errorf("log: cannot parse log types dump")
+	}
+	return types, nil
+}
@@ -0,0 +1,93 @@
+package log_test
+
+import (
+	"bytes"
+	stdlog "log"
+	"strings"
+	"testing"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/eal"
+	"github.com/tianyuansun/go-dpdk/log"
+)
+
+func TestLogCapture(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	id, err := log.Register("user.gotest")
+	assert(err == nil, err)
+	id1, err := log.Register("user.gotest")
+	assert(err == nil && id1 == id, err)
+
+	assert(log.SetLevel(id, log.Debug) == nil)
+	level, err := log.GetLevel(id)
+	assert(err == nil && level == log.Debug, level, err)
+
+	global := log.GlobalLevel()
+	defer log.SetGlobalLevel(global)
+	log.SetGlobalLevel(log.Debug)
+	assert(log.GlobalLevel() == log.Debug)
+	assert(log.CanLog(id, log.Info))
+
+	recs, err := log.Capture(func() {
+		assert(log.Log(log.Info, id, "hello") == nil)
+		assert(log.Logf(log.Debug, id, "hello %d", 2) == nil)
+	})
+	assert(err == nil, err)
+	assert(len(recs) == 2, recs)
+	assert(recs[0].Level == log.Info && recs[0].Type == id, recs[0])
+	assert(strings.HasSuffix(recs[0].Msg, "hello"), recs[0].Msg)
+	assert(recs[1].Level == log.Debug && strings.HasSuffix(recs[1].Msg, "hello 2"), recs[1])
+
+	// messages above type level are dropped
+	assert(log.SetLevel(id, log.Warning) == nil)
+	assert(!log.CanLog(id, log.Info))
+	recs, err = log.Capture(func() {
+		log.Log(log.Info, id, "dropped")
+		log.Log(log.Err, id, "error")
+	})
+	assert(err == nil, err)
+	assert(len(recs) == 1 && recs[0].Level == log.Err, recs)
+
+	assert(log.SetLevelPattern("user.gotest*", log.Debug) == nil)
+	assert(log.CanLog(id, log.Debug))
+	assert(log.SetLevelRegexp("^user\\.gotest$", log.Notice) == nil)
+	assert(!log.CanLog(id, log.Info) && log.CanLog(id, log.Notice))
+
+	types, err := log.Types()
+	assert(err == nil, err)
+	assert(types[id] == "user.gotest", types)
+	assert(types[log.TypeEAL] == "lib.eal", types[log.TypeEAL])
+
+	id2, err := log.RegisterLevel("user.gotest.level", log.Crit)
+	assert(err == nil, err)
+	level, err = log.GetLevel(id2)
+	assert(err == nil && level == log.Crit, level, err)
+}
+
+func TestLogHandler(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	id, err := log.Register("user.gotest.handler")
+	assert(err == nil, err)
+	assert(log.SetLevel(id, log.Info) == nil)
+
+	buf := &bytes.Buffer{}
+	prev, err := log.SetHandler(log.NewLoggerHandler(stdlog.New(buf, "", 0)))
+	assert(err == nil, err)
+	assert(prev == nil)
+
+	log.Log(log.Notice, id, "to logger")
+	assert(strings.Contains(buf.String(), "NOTICE: "), buf)
+	assert(strings.Contains(buf.String(), "to logger"), buf)
+
+	prev, err = log.SetHandler(nil)
+	assert(err == nil && prev != nil, err)
+
+	assert(log.Level(100).String() == "Level(100)")
+	assert(log.Warning.String() == "WARNING")
+}
@@ -0,0 +1,41 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlogLevel converts l into slog.Level. Levels from Emerg to Err are
+// converted into slog.LevelError and Notice into slog.LevelInfo.
+func (l Level) SlogLevel() slog.Level {
+	switch {
+	case l <= Err:
+		return slog.LevelError
+	case l == Warning:
+		return slog.LevelWarn
+	case l <= Info:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// NewSlogHandler returns Handler which passes records to h. The log
+// type id is added to records as "logtype" attribute.
+func NewSlogHandler(h slog.Handler) Handler {
+	return func(r *Record) {
+		ctx := context.Background()
+		level := r.Level.SlogLevel()
+		if !h.Enabled(ctx, level) {
+			return
+		}
+
+		rec := slog.NewRecord(time.Now(), level, r.Msg, 0)
+		rec.AddAttrs(slog.Int("logtype", r.Type))
+		h.Handle(ctx, rec)
+	}
+}
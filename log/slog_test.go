@@ -0,0 +1,48 @@
+//go:build go1.21
+// +build go1.21
+
+package log_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/eal"
+	"github.com/tianyuansun/go-dpdk/log"
+)
+
+func TestSlogHandler(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	assert(log.Err.SlogLevel() == slog.LevelError)
+	assert(log.Warning.SlogLevel() == slog.LevelWarn)
+	assert(log.Notice.SlogLevel() == slog.LevelInfo)
+	assert(log.Debug.SlogLevel() == slog.LevelDebug)
+
+	id, err := log.Register("user.gotest.slog")
+	assert(err == nil, err)
+	assert(log.SetLevel(id, log.Debug) == nil)
+
+	global := log.GlobalLevel()
+	defer log.SetGlobalLevel(global)
+	log.SetGlobalLevel(log.Debug)
+
+	buf := &bytes.Buffer{}
+	h := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	prev, err := log.SetHandler(log.NewSlogHandler(h))
+	assert(err == nil, err)
+	defer log.SetHandler(prev)
+
+	assert(log.Log(log.Err, id, "slog error") == nil)
+	assert(log.Log(log.Debug, id, "slog debug") == nil)
+
+	out := buf.String()
+	assert(strings.Contains(out, "level=ERROR"), out)
+	assert(strings.Contains(out, "slog error"), out)
+	assert(!strings.Contains(out, "slog debug"), out)
+}
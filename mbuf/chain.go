@@ -0,0 +1,175 @@
+package mbuf
+
+/*
+#include <rte_config.h>
+#include <rte_mbuf.h>
+*/
+import "C"
+
+import (
+	"io"
+	"syscall"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/mempool"
+)
+
+var _ io.ReaderAt = (*Mbuf)(nil)
+
+// NbSegs returns number of segments in the packet. The value is
+// valid only for the first segment.
+func (m *Mbuf) NbSegs() uint16 {
+	return uint16(m.nb_segs)
+}
+
+// DataLen returns amount of data in this segment.
+func (m *Mbuf) DataLen() uint16 {
+	return uint16(m.data_len)
+}
+
+// IsContiguous tells if packet data is contained in one segment.
+func (m *Mbuf) IsContiguous() bool {
+	return m.nb_segs == 1
+}
+
+// LastSeg returns the last segment of the packet.
+func (m *Mbuf) LastSeg() *Mbuf {
+	seg := m
+	for seg.next != nil {
+		seg = (*Mbuf)(seg.next)
+	}
+	return seg
+}
+
+// SegIter calls fn for each segment of the packet starting from m
+// until fn returns false. Returns number of iterated segments.
+func (m *Mbuf) SegIter(fn func(*Mbuf) bool) int {
+	n := 0
+	for seg := m; seg != nil; seg = (*Mbuf)(seg.next) {
+		n++
+		if !fn(seg) {
+			break
+		}
+	}
+	return n
+}
+
+// Segments returns all segments of the packet.
+func (m *Mbuf) Segments() []*Mbuf {
+	segs := make([]*Mbuf, 0, m.nb_segs)
+	m.SegIter(func(seg *Mbuf) bool {
+		segs = append(segs, seg)
+		return true
+	})
+	return segs
+}
+
+// ReadAt implements io.ReaderAt. It copies packet data starting at
+// offset off into p crossing segment boundaries if necessary. If the
+// packet holds less than len(p) bytes after off, io.EOF is returned
+// along with the number of copied bytes.
+func (m *Mbuf) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, common.IntErr(-int64(syscall.EINVAL))
+	}
+
+	if off >= int64(m.pkt_len) {
+		return 0, io.EOF
+	}
+
+	n := len(p)
+	if rem := int(int64(m.pkt_len) - off); n > rem {
+		n = rem
+	}
+
+	if data := m.PktMbufRead(int(off), n, p); data != nil && &data[0] != &p[0] {
+		copy(p, data)
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// PktMbufRead returns a slice of length bytes of packet data starting
+// at offset off, as in rte_pktmbuf_read. If the requested data is
+// contiguous in a segment the returned slice points directly into the
+// mbuf and buf is not used, so it may be nil. Otherwise, the data is
+// copied into buf and buf[:length] is returned.
+//
+// nil is returned if the packet is shorter than off+length, or if the
+// data spans multiple segments and buf is shorter than length.
+func (m *Mbuf) PktMbufRead(off, length int, buf []byte) []byte {
+	if off < 0 || length <= 0 {
+		return nil
+	}
+
+	var dst unsafe.Pointer
+	if len(buf) >= length {
+		dst = unsafe.Pointer(&buf[0])
+	} else if !m.rangeContiguous(off, length) {
+		return nil
+	}
+
+	ptr := C.rte_pktmbuf_read(ToCMbuf(m), C.uint32_t(off), C.uint32_t(length), dst)
+	if ptr == nil {
+		return nil
+	}
+	return unsafe.Slice((*byte)(ptr), length)
+}
+
+// rangeContiguous tells if length bytes of packet data starting at
+// offset off reside in one segment.
+func (m *Mbuf) rangeContiguous(off, length int) bool {
+	for seg := m; seg != nil; seg = (*Mbuf)(seg.next) {
+		if n := int(seg.data_len); off >= n {
+			off -= n
+		} else {
+			return off+length <= n
+		}
+	}
+	return false
+}
+
+// Linearize moves the data of a multi-segment packet into the first
+// segment and frees the rest of segments. The first segment must
+// have enough tailroom to hold the whole packet. If the packet is
+// already contiguous, nothing is done.
+func (m *Mbuf) Linearize() error {
+	var segs []*Mbuf
+	if TrackEnabled {
+		segs = m.Segments()[1:]
+	}
+
+	if C.rte_pktmbuf_linearize(ToCMbuf(m)) != 0 {
+		return common.IntErr(-int64(syscall.ENOSPC))
+	}
+
+	trackSegs(segs)
+	return nil
+}
+
+// Chain appends tail packet to head packet. The tail may be a
+// multi-segment packet itself. The packet length and the number of
+// segments of head are updated accordingly.
+//
+// If the number of segments exceeds maximum allowed value, error is
+// returned and both packets are left intact.
+func Chain(head, tail *Mbuf) error {
+	return common.IntErr(int64(C.rte_pktmbuf_chain(ToCMbuf(head), ToCMbuf(tail))))
+}
+
+// PktMbufCopy creates a full copy of length bytes of the packet
+// starting at offset off. The copy is allocated from mempool p and
+// may be a multi-segment packet if data room of p is not enough.
+// Specify length as math.MaxUint32 to copy everything after off.
+//
+// Unlike PktMbufClone, the copy doesn't share any data with the
+// original packet. NOTE: NULL may return if allocation fails.
+func (m *Mbuf) PktMbufCopy(p *mempool.Mempool, off, length uint32) *Mbuf {
+	cp := (*Mbuf)(C.rte_pktmbuf_copy(ToCMbuf(m), mp(p), C.uint32_t(off), C.uint32_t(length)))
+	trackAlloc(1, cp)
+	return cp
+}
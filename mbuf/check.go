@@ -0,0 +1,66 @@
+package mbuf
+
+/*
+#include <stdio.h>
+#include <rte_config.h>
+#include <rte_mbuf.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// ErrBadMbuf is returned by Check if the mbuf is corrupted.
+var ErrBadMbuf = errors.New("bad mbuf")
+
+// Check performs sanity check of the packet m and all of its
+// segments. It verifies mempool, buffer address, reference counter,
+// number of segments and packet length matching the sum of segment
+// data lengths. Returned error wraps ErrBadMbuf and describes the
+// first found inconsistency.
+func (m *Mbuf) Check() error {
+	if err := m.check(true); err != nil {
+		return err
+	}
+
+	for seg := m.Next(); seg != nil; seg = seg.Next() {
+		if err := seg.check(false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckSegment is like Check but checks only segment m itself and
+// skips the fields which are valid only in the first segment of a
+// packet.
+func (m *Mbuf) CheckSegment() error {
+	return m.check(false)
+}
+
+func (m *Mbuf) check(isHeader bool) error {
+	var reason *C.char
+	var hdr C.int
+	if isHeader {
+		hdr = 1
+	}
+
+	if C.rte_mbuf_check(ToCMbuf(m), hdr, &reason) != 0 {
+		return fmt.Errorf("%w %p: %s", ErrBadMbuf, m, C.GoString(reason))
+	}
+	return nil
+}
+
+// Dump writes header fields of the packet m and hex dump of at most
+// maxBytes of its data into w. Each segment is dumped separately.
+func (m *Mbuf) Dump(w io.Writer, maxBytes int) error {
+	return common.DumpTo(w, func(fp unsafe.Pointer) {
+		C.rte_pktmbuf_dump((*C.FILE)(fp), ToCMbuf(m), C.uint(maxBytes))
+	})
+}
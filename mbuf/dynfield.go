@@ -0,0 +1,234 @@
+package mbuf
+
+/*
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <rte_config.h>
+#include <rte_mbuf.h>
+#include <rte_mbuf_dyn.h>
+
+static int go_dynfield_register(const char *name, size_t size,
+		size_t align, int req)
+{
+	struct rte_mbuf_dynfield params = {
+		.size = size,
+		.align = align,
+	};
+	strncpy(params.name, name, sizeof(params.name) - 1);
+	return rte_mbuf_dynfield_register_offset(&params, req);
+}
+
+static int go_dynflag_register(const char *name, unsigned int req)
+{
+	struct rte_mbuf_dynflag params = {};
+	strncpy(params.name, name, sizeof(params.name) - 1);
+	return rte_mbuf_dynflag_register_bitnum(&params, req);
+}
+*/
+import "C"
+
+import (
+	"io"
+	"math/bits"
+	"syscall"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// Names of dynamic fields and flags registered by DPDK libraries and
+// PMDs.
+const (
+	DynFieldTimestampName  = C.RTE_MBUF_DYNFIELD_TIMESTAMP_NAME
+	DynFlagRxTimestampName = C.RTE_MBUF_DYNFLAG_RX_TIMESTAMP_NAME
+	DynFlagTxTimestampName = C.RTE_MBUF_DYNFLAG_TX_TIMESTAMP_NAME
+	DynFieldMetadataName   = C.RTE_MBUF_DYNFIELD_METADATA_NAME
+	DynFlagMetadataName    = C.RTE_MBUF_DYNFLAG_METADATA_NAME
+)
+
+// DynNameSize is the maximum length of dynamic field or flag name
+// including terminating zero.
+const DynNameSize = C.RTE_MBUF_DYN_NAMESIZE
+
+// checkDynName rejects names which would be truncated in C.
+func checkDynName(name string) error {
+	if len(name) >= DynNameSize {
+		return syscall.ENAMETOOLONG
+	}
+	return nil
+}
+
+// DynField is a handle to dynamic mbuf field of type T. Use it to
+// access per-packet metadata registered in the mbuf layout.
+//
+// The field is stored in mbuf memory which is invisible to the Go
+// garbage collector, so T must not contain Go pointers.
+type DynField[T any] struct {
+	off uintptr
+}
+
+// RegisterDynField registers dynamic field with specified name. The
+// size and alignment of the field are taken from T. If the field
+// with the same name and parameters already exists, its handle is
+// returned.
+func RegisterDynField[T any](name string) (DynField[T], error) {
+	return RegisterDynFieldOffset[T](name, -1)
+}
+
+// RegisterDynFieldOffset registers dynamic field with specified name
+// at the requested offset in mbuf. If off is -1, any offset is used.
+// syscall.ENAMETOOLONG is returned if the name does not fit in
+// DynNameSize.
+func RegisterDynFieldOffset[T any](name string, off int) (DynField[T], error) {
+	var zero T
+
+	if err := checkDynName(name); err != nil {
+		return DynField[T]{}, err
+	}
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	n := C.go_dynfield_register(cname, C.size_t(unsafe.Sizeof(zero)),
+		C.size_t(unsafe.Alignof(zero)), C.int(off))
+	if n < 0 {
+		return DynField[T]{}, common.RteErrno()
+	}
+
+	return DynField[T]{off: uintptr(n)}, nil
+}
+
+// LookupDynField looks up dynamic field registered by other component
+// or PMD. syscall.EINVAL is returned if the size of the field is less
+// than the size of T.
+func LookupDynField[T any](name string) (DynField[T], error) {
+	var zero T
+	var params C.struct_rte_mbuf_dynfield
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	n := C.rte_mbuf_dynfield_lookup(cname, &params)
+	if n < 0 {
+		return DynField[T]{}, common.RteErrno()
+	}
+
+	if uintptr(params.size) < unsafe.Sizeof(zero) {
+		return DynField[T]{}, common.IntErr(-int64(syscall.EINVAL))
+	}
+
+	return DynField[T]{off: uintptr(n)}, nil
+}
+
+// Offset returns offset of the field in mbuf.
+func (f DynField[T]) Offset() uintptr {
+	return f.off
+}
+
+// Ptr returns pointer to the field in mbuf m.
+func (f DynField[T]) Ptr(m *Mbuf) *T {
+	return (*T)(unsafe.Add(unsafe.Pointer(m), f.off))
+}
+
+// Get returns value of the field in mbuf m.
+func (f DynField[T]) Get(m *Mbuf) T {
+	return *f.Ptr(m)
+}
+
+// Set sets value of the field in mbuf m.
+func (f DynField[T]) Set(m *Mbuf, v T) {
+	*f.Ptr(m) = v
+}
+
+// DynFlag is a handle to dynamic mbuf offload flag.
+type DynFlag struct {
+	bit uint
+}
+
+// RegisterDynFlag registers dynamic flag with specified name. If the
+// flag with the same name already exists, its handle is returned.
+func RegisterDynFlag(name string) (DynFlag, error) {
+	return RegisterDynFlagBitnum(name, -1)
+}
+
+// RegisterDynFlagBitnum registers dynamic flag with specified name at
+// the requested bit number in ol_flags. If bit is -1, any free bit is
+// used. syscall.ENAMETOOLONG is returned if the name does not fit in
+// DynNameSize.
+func RegisterDynFlagBitnum(name string, bit int) (DynFlag, error) {
+	if err := checkDynName(name); err != nil {
+		return DynFlag{}, err
+	}
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	n := C.go_dynflag_register(cname, C.uint(bit))
+	if n < 0 {
+		return DynFlag{}, common.RteErrno()
+	}
+
+	return DynFlag{bit: uint(n)}, nil
+}
+
+// LookupDynFlag looks up dynamic flag registered by other component
+// or PMD.
+func LookupDynFlag(name string) (DynFlag, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	n := C.rte_mbuf_dynflag_lookup(cname, nil)
+	if n < 0 {
+		return DynFlag{}, common.RteErrno()
+	}
+
+	return DynFlag{bit: uint(n)}, nil
+}
+
+// Bitnum returns bit number of the flag in ol_flags.
+func (f DynFlag) Bitnum() uint {
+	return f.bit
+}
+
+// Mask returns the flag as offload flags mask.
+func (f DynFlag) Mask() OlFlags {
+	return OlFlags(1) << f.bit
+}
+
+// Test tells if the flag is set in mbuf m.
+func (f DynFlag) Test(m *Mbuf) bool {
+	return m.OlFlags().Has(f.Mask())
+}
+
+// Set sets the flag in mbuf m.
+func (f DynFlag) Set(m *Mbuf) {
+	m.AddOlFlags(f.Mask())
+}
+
+// Clear clears the flag in mbuf m.
+func (f DynFlag) Clear(m *Mbuf) {
+	m.ClearOlFlags(f.Mask())
+}
+
+// RegisterRxTimestamp registers the RX timestamp field and flag as
+// used by PMDs with RTE_ETH_RX_OFFLOAD_TIMESTAMP.
+func RegisterRxTimestamp() (DynField[uint64], DynFlag, error) {
+	var off C.int
+	var flag C.uint64_t
+
+	if C.rte_mbuf_dyn_rx_timestamp_register(&off, &flag) < 0 {
+		return DynField[uint64]{}, DynFlag{}, common.RteErrno()
+	}
+
+	return DynField[uint64]{off: uintptr(off)},
+		DynFlag{bit: uint(bits.TrailingZeros64(uint64(flag)))}, nil
+}
+
+// DynDump writes the list of registered dynamic fields and flags into
+// w.
+func DynDump(w io.Writer) error {
+	return common.DumpTo(w, func(fp unsafe.Pointer) {
+		C.rte_mbuf_dyn_dump((*C.FILE)(fp))
+	})
+}
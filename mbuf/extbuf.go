@@ -0,0 +1,95 @@
+package mbuf
+
+/*
+#include <stdlib.h>
+#include <rte_config.h>
+#include <rte_mbuf.h>
+
+extern void goExtBufFree(void *addr, void *opaque);
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+var (
+	extFreeCallbacks = common.NewRegistryMap()
+)
+
+// ExtSharedInfo is the shared data of external buffer. It contains
+// reference counter and free callback of the buffer shared by all
+// mbufs attached to it.
+type ExtSharedInfo C.struct_rte_mbuf_ext_shared_info
+
+//export goExtBufFree
+func goExtBufFree(addr, opaque unsafe.Pointer) {
+	cb := *(*common.ObjectID)(opaque)
+	C.free(opaque)
+	fn := extFreeCallbacks.Read(cb).(func(unsafe.Pointer))
+	extFreeCallbacks.Delete(cb)
+	fn(addr)
+}
+
+// ExtShinfoInitHelper initializes shared data at the end of external
+// buffer buf of length *bufLen. *bufLen is decreased by the size of
+// shared data and the alignment padding so that it designates the
+// usable part of the buffer. fn is called with buf as an argument
+// once the last mbuf attached to the buffer is freed.
+//
+// nil is returned if the buffer is too small to hold the shared data.
+func ExtShinfoInitHelper(buf unsafe.Pointer, bufLen *uint16, fn func(buf unsafe.Pointer)) *ExtSharedInfo {
+	cb := extFreeCallbacks.Create(fn)
+	opaque := (*common.ObjectID)(C.malloc(C.size_t(unsafe.Sizeof(cb))))
+	*opaque = cb
+
+	n := C.uint16_t(*bufLen)
+	shinfo := C.rte_pktmbuf_ext_shinfo_init_helper(buf, &n,
+		(*[0]byte)(C.goExtBufFree), unsafe.Pointer(opaque))
+	if shinfo == nil {
+		extFreeCallbacks.Delete(cb)
+		C.free(unsafe.Pointer(opaque))
+		return nil
+	}
+
+	*bufLen = uint16(n)
+	return (*ExtSharedInfo)(shinfo)
+}
+
+// RefCntRead returns reference counter of external buffer.
+func (s *ExtSharedInfo) RefCntRead() uint16 {
+	return uint16(C.rte_mbuf_ext_refcnt_read((*C.struct_rte_mbuf_ext_shared_info)(s)))
+}
+
+// RefCntSet sets reference counter of external buffer.
+func (s *ExtSharedInfo) RefCntSet(v uint16) {
+	C.rte_mbuf_ext_refcnt_set((*C.struct_rte_mbuf_ext_shared_info)(s), C.uint16_t(v))
+}
+
+// RefCntUpdate adds v to reference counter of external buffer and
+// returns its new value.
+func (s *ExtSharedInfo) RefCntUpdate(v int16) uint16 {
+	return uint16(C.rte_mbuf_ext_refcnt_update((*C.struct_rte_mbuf_ext_shared_info)(s), C.int16_t(v)))
+}
+
+// AttachExtBuf attaches external buffer buf of length bufLen with IO
+// address iova to mbuf m. The mbuf must be direct and not attached to
+// any other buffer. shinfo is normally initialized by
+// ExtShinfoInitHelper and its reference counter must be incremented
+// by the caller if the buffer is attached to more than one mbuf.
+//
+// Data of attached mbuf is empty and starts at the beginning of buf.
+// Use AppendMbuf to declare the payload already present in buf
+// without copying.
+func (m *Mbuf) AttachExtBuf(buf unsafe.Pointer, iova uint64, bufLen uint16, shinfo *ExtSharedInfo) {
+	C.rte_pktmbuf_attach_extbuf(ToCMbuf(m), buf, C.rte_iova_t(iova), C.uint16_t(bufLen),
+		(*C.struct_rte_mbuf_ext_shared_info)(shinfo))
+}
+
+// ExtSharedInfo returns shared data of external buffer attached to m.
+// It is meaningful only if m is attached to external buffer.
+func (m *Mbuf) ExtSharedInfo() *ExtSharedInfo {
+	return (*ExtSharedInfo)(m.shinfo)
+}
@@ -0,0 +1,100 @@
+package mbuf
+
+/*
+#include "offload.h"
+*/
+import "C"
+
+import (
+	"github.com/tianyuansun/go-dpdk/mempool"
+)
+
+// IsIndirect tells if m is attached to a buffer of another mbuf,
+// i.e. it's a clone.
+func (m *Mbuf) IsIndirect() bool {
+	return m.ol_flags&C.RTE_MBUF_F_INDIRECT != 0
+}
+
+// IsExternal tells if m is attached to an external buffer.
+func (m *Mbuf) IsExternal() bool {
+	return m.ol_flags&C.RTE_MBUF_F_EXTERNAL != 0
+}
+
+// IsDirect tells if m owns its data buffer, i.e. it is neither
+// indirect nor attached to an external buffer.
+func (m *Mbuf) IsDirect() bool {
+	return m.ol_flags&(C.RTE_MBUF_F_INDIRECT|C.RTE_MBUF_F_EXTERNAL) == 0
+}
+
+// Attach attaches m to the data buffer of md making m indirect.
+// Reference counter of md is incremented. m must be direct, have
+// reference counter of 1 and be a single segment packet with no
+// data. If md is itself indirect, m is attached to its direct mbuf.
+// If md is attached to an external buffer, m is attached to the same
+// buffer.
+func (m *Mbuf) Attach(md *Mbuf) {
+	C.rte_pktmbuf_attach(ToCMbuf(m), ToCMbuf(md))
+}
+
+// Detach restores the original data buffer of indirect or external
+// mbuf m. Reference counter of the direct mbuf or external buffer is
+// decremented and it is freed if the counter drops to zero.
+func (m *Mbuf) Detach() {
+	C.rte_pktmbuf_detach(ToCMbuf(m))
+}
+
+// DirectMbuf returns the direct mbuf m is attached to. It is valid
+// only if m is indirect.
+func (m *Mbuf) DirectMbuf() *Mbuf {
+	return (*Mbuf)(C.rte_mbuf_from_indirect(ToCMbuf(m)))
+}
+
+// Multicast fills out with packets each consisting of a private
+// header segment followed by a clone of payload. Every header is
+// allocated from hdrPool and has hdrLen bytes of data reserved for
+// the caller to write a header into. Clones are allocated from
+// clonePool which should have zero data room.
+//
+// Payload data is shared by all packets and the caller still owns its
+// reference to payload. The data is released once payload and all
+// the packets in out are freed.
+//
+// If any allocation fails, all packets created so far are freed and
+// ErrNullData is returned.
+func Multicast(payload *Mbuf, hdrPool, clonePool *mempool.Mempool, hdrLen uint16, out []*Mbuf) error {
+	for i := range out {
+		pkt, err := mcastPacket(payload, hdrPool, clonePool, hdrLen)
+		if err != nil {
+			PktMbufFreeBulk(out[:i])
+			return err
+		}
+		out[i] = pkt
+	}
+	return nil
+}
+
+func mcastPacket(payload *Mbuf, hdrPool, clonePool *mempool.Mempool, hdrLen uint16) (*Mbuf, error) {
+	hdr := PktMbufAlloc(hdrPool)
+	if hdr == nil {
+		return nil, ErrNullData
+	}
+
+	if !AppendMbuf(hdr, uint(hdrLen)) {
+		hdr.PktMbufFree()
+		return nil, ErrNullData
+	}
+
+	clone := payload.PktMbufClone(clonePool)
+	if clone == nil {
+		hdr.PktMbufFree()
+		return nil, ErrNullData
+	}
+
+	if err := Chain(hdr, clone); err != nil {
+		clone.PktMbufFree()
+		hdr.PktMbufFree()
+		return nil, err
+	}
+
+	return hdr, nil
+}
@@ -0,0 +1,16 @@
+package mbuf
+
+// Leak is a group of outstanding mbufs allocated at the same call
+// stack. Mbufs are tracked only if built with 'mbuftrack' tag.
+//
+// Tracking covers mbufs allocated with PktMbufAlloc,
+// PktMbufAllocBulk, AllocResetAndAppend, PktMbufClone and
+// PktMbufCopy, and released with PktMbufFree, PktMbufFreeBulk,
+// RawFree, Untrack and Handover. Freeing a packet forgets all of its segments
+// unless they are still referenced elsewhere.
+type Leak struct {
+	// Stack is the formatted call stack of allocation.
+	Stack string
+	// Mbufs are outstanding mbufs allocated at Stack.
+	Mbufs []*Mbuf
+}
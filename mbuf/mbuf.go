@@ -81,35 +81,44 @@ func mp(m *mempool.Mempool) *C.struct_rte_mempool {
 // PktMbufFree returns this mbuf into its originating mempool along
 // with all its segments.
 func (m *Mbuf) PktMbufFree() {
+	trackFree(m)
 	C.rte_pktmbuf_free(ToCMbuf(m))
 }
 
 // RawFree returns this mbuf into its originating mempool.
 func (m *Mbuf) RawFree() {
+	trackSegs([]*Mbuf{m})
 	C.rte_mbuf_raw_free(ToCMbuf(m))
 }
 
 // PktMbufClone clones the mbuf using supplied mempool as the buffer
 // source. NOTE: NULL may return if allocation fails.
 func (m *Mbuf) PktMbufClone(p *mempool.Mempool) *Mbuf {
-	return (*Mbuf)(C.rte_pktmbuf_clone(ToCMbuf(m), mp(p)))
+	clone := (*Mbuf)(C.rte_pktmbuf_clone(ToCMbuf(m), mp(p)))
+	trackAlloc(1, clone)
+	return clone
 }
 
 // PktMbufAlloc allocate an uninitialized mbuf from mempool p.
 // Note that NULL may be returned if allocation failed.
 func PktMbufAlloc(p *mempool.Mempool) *Mbuf {
-	m := C.rte_pktmbuf_alloc(mp(p))
-	return (*Mbuf)(m)
+	m := (*Mbuf)(C.rte_pktmbuf_alloc(mp(p)))
+	trackAlloc(1, m)
+	return m
 }
 
 // PktMbufAllocBulk allocate a bulk of mbufs.
 func PktMbufAllocBulk(p *mempool.Mempool, ms []*Mbuf) error {
 	e := C.rte_pktmbuf_alloc_bulk(mp(p), mbufs(ms), C.uint(len(ms)))
+	if e == 0 {
+		trackAlloc(1, ms...)
+	}
 	return common.IntErr(int64(e))
 }
 
 // PktMbufReset frees a bulk of packet mbufs back into their original mempools.
 func PktMbufFreeBulk(ms []*Mbuf) {
+	trackFree(ms...)
 	C.free_bulk(mbufs(ms), C.uint(len(ms)))
 }
 
@@ -180,58 +189,6 @@ func SetNextMbuf(next *Mbuf, prev *Mbuf) {
 	prev.next = (*C.struct_rte_mbuf)(next)
 }
 
-// func setMbufLen(mb *Mbuf, l2len, l3len uint32) {
-// 	// Assign l2_len:7 and l3_len:9 fields in rte_mbuf
-// 	mb.anon5[0] = uint8((l2len & 0x7f) | ((l3len & 1) << 7))
-// 	mb.anon5[1] = uint8(l3len >> 1)
-// 	mb.anon5[2] = 0
-// 	mb.anon5[3] = 0
-// 	mb.anon5[4] = 0
-// 	mb.anon5[5] = 0
-// 	mb.anon5[6] = 0
-// 	mb.anon5[7] = 0
-// }
-
-// SetTXIPv4OLFlags sets mbuf flags for IPv4 header
-// checksum calculation hardware offloading.
-// func SetTXIPv4OLFlags(mb *Mbuf, l2len, l3len uint32) {
-// 	// PKT_TX_IP_CKSUM | PKT_TX_IPV4
-// 	mb.ol_flags = (1 << 54) | (1 << 55)
-// 	setMbufLen(mb, l2len, l3len)
-// }
-
-// SetTXIPv4UDPOLFlags sets mbuf flags for IPv4 and UDP
-// headers checksum calculation hardware offloading.
-// func SetTXIPv4UDPOLFlags(mb *Mbuf, l2len, l3len uint32) {
-// 	// PKT_TX_UDP_CKSUM | PKT_TX_IP_CKSUM | PKT_TX_IPV4
-// 	mb.ol_flags = (3 << 52) | (1 << 54) | (1 << 55)
-// 	setMbufLen(mb, l2len, l3len)
-// }
-
-// SetTXIPv4TCPOLFlags sets mbuf flags for IPv4 and TCP
-// headers checksum calculation hardware offloading.
-// func SetTXIPv4TCPOLFlags(mb *Mbuf, l2len, l3len uint32) {
-// 	// PKT_TX_TCP_CKSUM | PKT_TX_IP_CKSUM | PKT_TX_IPV4
-// 	mb.ol_flags = (1 << 52) | (1 << 54) | (1 << 55)
-// 	setMbufLen(mb, l2len, l3len)
-// }
-
-// SetTXIPv6UDPOLFlags sets mbuf flags for IPv6 UDP header
-// checksum calculation hardware offloading.
-// func SetTXIPv6UDPOLFlags(mb *Mbuf, l2len, l3len uint32) {
-// 	// PKT_TX_UDP_CKSUM | PKT_TX_IPV6
-// 	mb.ol_flags = (3 << 52) | (1 << 56)
-// 	setMbufLen(mb, l2len, l3len)
-// }
-
-// SetTXIPv6TCPOLFlags sets mbuf flags for IPv6 TCP
-// header checksum calculation hardware offloading.
-// func SetTXIPv6TCPOLFlags(mb *Mbuf, l2len, l3len uint32) {
-// 	// PKT_TX_TCP_CKSUM | PKT_TX_IPV4
-// 	mb.ol_flags = (1 << 52) | (1 << 56)
-// 	setMbufLen(mb, l2len, l3len)
-// }
-
 // GetRawPacketBytesMbuf returns raw data from packet.
 func GetRawPacketBytesMbuf(mb *Mbuf) []byte {
 	dataLen := uintptr(mb.data_len)
@@ -380,8 +337,9 @@ func (m *Mbuf) ResetAndAppend(data *common.CStruct) error {
 // mempool from which the mbuf is allocated. Data is C array
 // representation of data to add.
 func AllocResetAndAppend(p *mempool.Mempool, data *common.CStruct) *Mbuf {
-	m := C.alloc_reset_and_append(mp(p), data.Ptr, C.size_t(data.Len))
-	return (*Mbuf)(unsafe.Pointer(m))
+	m := (*Mbuf)(unsafe.Pointer(C.alloc_reset_and_append(mp(p), data.Ptr, C.size_t(data.Len))))
+	trackAlloc(1, m)
+	return m
 }
 
 // HeadRoomSize returns the value of the data_off field,
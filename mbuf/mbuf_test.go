@@ -2,12 +2,18 @@ package mbuf
 
 import (
 	"crypto/rand"
+	"io"
+	"math"
+	"strings"
+	"syscall"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tianyuansun/go-dpdk/common"
 	"github.com/tianyuansun/go-dpdk/eal"
 	"github.com/tianyuansun/go-dpdk/mempool"
+	"github.com/tianyuansun/go-dpdk/memzone"
 )
 
 func getSample(n int) []byte {
@@ -71,3 +77,515 @@ func TestPktMbufFreeBulk(t *testing.T) {
 	m.RefCntSet(1)
 	assert.Equal(t, m.RefCntRead(), uint16(1))
 }
+
+func TestOffload(t *testing.T) {
+	eal.InitOnceSafe("test-mbuf", 1)
+
+	mp, err := mempool.CreateMbufPool("test-pool-offload", 100, 1500)
+	assert.NoError(t, err)
+	defer mp.Free()
+
+	m := PktMbufAlloc(mp)
+	assert.NotNil(t, m)
+	defer m.PktMbufFree()
+
+	assert.Zero(t, m.OlFlags())
+	assert.Zero(t, m.TxOffload())
+
+	m.SetOlFlags(TxIPv4 | TxIPCksum)
+	m.AddOlFlags(TxTCPSeg | TxTCPCksum)
+	assert.True(t, m.OlFlags().Has(TxIPv4|TxIPCksum|TxTCPSeg))
+	m.ClearOlFlags(TxTCPSeg)
+	assert.False(t, m.OlFlags().Has(TxTCPSeg))
+	assert.Equal(t, m.OlFlags()&TxL4Mask, TxTCPCksum)
+
+	m.SetL2Len(14)
+	m.SetL3Len(20)
+	m.SetL4Len(20)
+	m.SetTsoSegsz(1460)
+	m.SetOuterL2Len(14)
+	m.SetOuterL3Len(40)
+	assert.Equal(t, m.L2Len(), uint16(14))
+	assert.Equal(t, m.L3Len(), uint16(20))
+	assert.Equal(t, m.L4Len(), uint16(20))
+	assert.Equal(t, m.TsoSegsz(), uint16(1460))
+	assert.Equal(t, m.OuterL2Len(), uint16(14))
+	assert.Equal(t, m.OuterL3Len(), uint16(40))
+
+	// overflow doesn't affect neighbouring fields
+	m.SetL2Len(0xff)
+	assert.Equal(t, m.L2Len(), uint16(0x7f))
+	assert.Equal(t, m.L3Len(), uint16(20))
+
+	tx := m.TxOffload()
+	m.SetTxOffload(0x7f, 20, 20, 1460, 40, 14)
+	assert.Equal(t, m.TxOffload(), tx)
+
+	// rx flags
+	m.SetOlFlags(RxIPCksumGood | RxL4CksumBad | RxRssHash)
+	assert.True(t, m.OlFlags().IPCksumGood())
+	assert.False(t, m.OlFlags().IPCksumBad())
+	assert.True(t, m.OlFlags().L4CksumBad())
+	assert.True(t, m.OlFlags().RssHashValid())
+	assert.False(t, m.OlFlags().VlanStripped())
+	m.SetOlFlags(RxIPCksumNone)
+	assert.False(t, m.OlFlags().IPCksumGood())
+	assert.False(t, m.OlFlags().IPCksumBad())
+}
+
+func TestPacketType(t *testing.T) {
+	eal.InitOnceSafe("test-mbuf", 1)
+
+	mp, err := mempool.CreateMbufPool("test-pool-ptype", 100, 1500)
+	assert.NoError(t, err)
+	defer mp.Free()
+
+	m := PktMbufAlloc(mp)
+	assert.NotNil(t, m)
+	defer m.PktMbufFree()
+
+	// Ethernet + IPv4 + UDP
+	frame := make([]byte, 14+20+8+16)
+	frame[12], frame[13] = 0x08, 0x00 // EtherType IPv4
+	frame[14] = 0x45                  // version 4, IHL 5
+	frame[16], frame[17] = 0, 20+8+16
+	frame[14+9] = 17 // UDP
+	assert.NoError(t, m.PktMbufAppend(frame))
+
+	assert.Equal(t, m.PacketType(), PtypeUnknown)
+
+	var lens HdrLens
+	p := m.NetGetPtype(PtypeAllMask, &lens)
+	assert.Equal(t, p, PtypeL2Ether|PtypeL3IPv4|PtypeL4UDP)
+	assert.Equal(t, lens.L2Len, uint8(14))
+	assert.Equal(t, lens.L3Len, uint16(20))
+	assert.Equal(t, lens.L4Len, uint8(8))
+	assert.Equal(t, p.String(), "L2_ETHER L3_IPV4 L4_UDP")
+	assert.True(t, p.IsIPv4())
+	assert.False(t, p.IsIPv6())
+	assert.False(t, p.IsTunnel())
+	assert.Equal(t, p.L3(), PtypeL3IPv4)
+	assert.Equal(t, p.L4(), PtypeL4UDP)
+
+	// parsing stops at requested layer
+	p = m.NetGetPtype(PtypeL2Mask|PtypeL3Mask, nil)
+	assert.Equal(t, p.L4(), PtypeUnknown)
+
+	// not stored by NetGetPtype
+	assert.Equal(t, m.PacketType(), PtypeUnknown)
+
+	p = m.Classify()
+	assert.Equal(t, m.PacketType(), p)
+	assert.Equal(t, m.L2Len(), uint16(14))
+	assert.Equal(t, m.L3Len(), uint16(20))
+	assert.Equal(t, m.L4Len(), uint16(8))
+
+	// driver-provided packet type is trusted
+	m.SetPacketType(PtypeL2Ether | PtypeL3IPv6)
+	assert.Equal(t, m.Classify(), PtypeL2Ether|PtypeL3IPv6)
+}
+
+func TestChain(t *testing.T) {
+	eal.InitOnceSafe("test-mbuf", 1)
+
+	// 64 bytes of data room per segment after the default headroom
+	small, err := mempool.CreateMbufPool("test-pool-chain-small", 100, 128+64)
+	assert.NoError(t, err)
+	defer small.Free()
+
+	big, err := mempool.CreateMbufPool("test-pool-chain-big", 10, 1500)
+	assert.NoError(t, err)
+	defer big.Free()
+
+	sample := getSample(200)
+
+	// build 4-segment packet: 64+64+64+8
+	head := PktMbufAlloc(small)
+	assert.NotNil(t, head)
+	assert.NoError(t, head.PktMbufAppend(sample[:64]))
+	for off := 64; off < len(sample); off += 64 {
+		end := off + 64
+		if end > len(sample) {
+			end = len(sample)
+		}
+		seg := PktMbufAlloc(small)
+		assert.NotNil(t, seg)
+		assert.NoError(t, seg.PktMbufAppend(sample[off:end]))
+		assert.NoError(t, Chain(head, seg))
+	}
+
+	assert.Equal(t, head.NbSegs(), uint16(4))
+	assert.Equal(t, head.PktLen(), uint32(200))
+	assert.False(t, head.IsContiguous())
+	assert.Equal(t, head.LastSeg().DataLen(), uint16(8))
+	assert.Equal(t, len(head.Segments()), 4)
+	assert.Equal(t, head.SegIter(func(*Mbuf) bool { return false }), 1)
+	assert.Equal(t, small.InUseCount(), 4)
+
+	// read across segment boundaries
+	buf := make([]byte, 100)
+	n, err := head.ReadAt(buf, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, n, 100)
+	assert.Equal(t, buf, sample[50:150])
+
+	n, err = head.ReadAt(buf, 150)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, n, 50)
+	assert.Equal(t, buf[:n], sample[150:])
+
+	n, err = head.ReadAt(buf, 200)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Zero(t, n)
+
+	// contiguous read points into the segment
+	data := head.PktMbufRead(66, 10, nil)
+	assert.Equal(t, data, sample[66:76])
+	assert.Equal(t, head.PktMbufRead(66, 10, []byte{}), sample[66:76])
+	assert.Nil(t, head.PktMbufRead(60, 10, nil))
+	assert.Equal(t, head.PktMbufRead(60, 10, buf), sample[60:70])
+	assert.Nil(t, head.PktMbufRead(195, 10, buf))
+
+	// deep copy of a range spans multiple segments of small pool
+	cp := head.PktMbufCopy(small, 10, 150)
+	assert.NotNil(t, cp)
+	assert.Equal(t, cp.PktLen(), uint32(150))
+	assert.Equal(t, cp.NbSegs(), uint16(3))
+	n, err = cp.ReadAt(buf[:100], 0)
+	assert.NoError(t, err)
+	assert.Equal(t, buf[:n], sample[10:110])
+	assert.Equal(t, small.InUseCount(), 7)
+	cp.PktMbufFree()
+	assert.Equal(t, small.InUseCount(), 4)
+
+	// copy of the whole packet into the big pool is contiguous
+	cp = head.PktMbufCopy(big, 0, math.MaxUint32)
+	assert.NotNil(t, cp)
+	assert.True(t, cp.IsContiguous())
+	assert.Equal(t, cp.Data(), sample)
+	cp.PktMbufFree()
+
+	// small head segment has no tailroom for the whole packet
+	assert.Error(t, head.Linearize())
+	assert.Equal(t, head.NbSegs(), uint16(4))
+
+	// chain into a head with enough tailroom and linearize
+	m := PktMbufAlloc(big)
+	assert.NotNil(t, m)
+	assert.NoError(t, Chain(m, head))
+	assert.Equal(t, m.NbSegs(), uint16(5))
+	assert.Equal(t, m.PktLen(), uint32(200))
+
+	assert.NoError(t, m.Linearize())
+	assert.True(t, m.IsContiguous())
+	assert.Equal(t, m.Data(), sample)
+	assert.Zero(t, small.InUseCount())
+
+	m.PktMbufFree()
+	assert.Zero(t, big.InUseCount())
+}
+
+func TestDynField(t *testing.T) {
+	eal.InitOnceSafe("test-mbuf", 1)
+
+	mp, err := mempool.CreateMbufPool("test-pool-dyn", 100, 1500)
+	assert.NoError(t, err)
+	defer mp.Free()
+
+	m := PktMbufAlloc(mp)
+	assert.NotNil(t, m)
+	defer m.PktMbufFree()
+
+	tenant, err := RegisterDynField[uint32]("test_dynfield_tenant")
+	assert.NoError(t, err)
+	assert.NotZero(t, tenant.Offset())
+
+	tenant.Set(m, 0xdeadbeef)
+	assert.Equal(t, tenant.Get(m), uint32(0xdeadbeef))
+
+	// same registration yields the same field
+	again, err := RegisterDynField[uint32]("test_dynfield_tenant")
+	assert.NoError(t, err)
+	assert.Equal(t, again.Offset(), tenant.Offset())
+
+	found, err := LookupDynField[uint32]("test_dynfield_tenant")
+	assert.NoError(t, err)
+	assert.Equal(t, found.Get(m), uint32(0xdeadbeef))
+
+	// field is too small for uint64
+	_, err = LookupDynField[uint64]("test_dynfield_tenant")
+	assert.Error(t, err)
+
+	_, err = LookupDynField[uint32]("test_dynfield_missing")
+	assert.Error(t, err)
+
+	// names are not truncated
+	long := strings.Repeat("x", DynNameSize)
+	_, err = RegisterDynField[uint32](long)
+	assert.Equal(t, syscall.ENAMETOOLONG, err)
+	_, err = RegisterDynFlag(long)
+	assert.Equal(t, syscall.ENAMETOOLONG, err)
+
+	flag, err := RegisterDynFlag("test_dynflag_classified")
+	assert.NoError(t, err)
+	assert.False(t, flag.Test(m))
+	flag.Set(m)
+	assert.True(t, flag.Test(m))
+	assert.True(t, m.OlFlags().Has(flag.Mask()))
+	flag.Clear(m)
+	assert.False(t, flag.Test(m))
+
+	f, err := LookupDynFlag("test_dynflag_classified")
+	assert.NoError(t, err)
+	assert.Equal(t, f.Bitnum(), flag.Bitnum())
+
+	ts, tsFlag, err := RegisterRxTimestamp()
+	assert.NoError(t, err)
+	ts.Set(m, 12345)
+	assert.Equal(t, ts.Get(m), uint64(12345))
+
+	tsField, err := LookupDynField[uint64](DynFieldTimestampName)
+	assert.NoError(t, err)
+	assert.Equal(t, tsField.Offset(), ts.Offset())
+
+	f, err = LookupDynFlag(DynFlagRxTimestampName)
+	assert.NoError(t, err)
+	assert.Equal(t, f.Bitnum(), tsFlag.Bitnum())
+
+	var sb strings.Builder
+	assert.NoError(t, DynDump(&sb))
+	assert.Contains(t, sb.String(), "test_dynfield_tenant")
+	assert.Contains(t, sb.String(), "test_dynflag_classified")
+}
+
+func TestExtBuf(t *testing.T) {
+	eal.InitOnceSafe("test-mbuf", 1)
+
+	mp, err := mempool.CreateMbufPool("test-pool-extbuf", 100, 1500)
+	assert.NoError(t, err)
+	defer mp.Free()
+
+	mz, err := memzone.Reserve("test-mz-extbuf", 4096)
+	assert.NoError(t, err)
+	defer mz.Free()
+
+	sample := getSample(100)
+	copy(mz.Bytes(), sample)
+
+	var freed []unsafe.Pointer
+	bufLen := uint16(mz.Len())
+	shinfo := ExtShinfoInitHelper(mz.Addr(), &bufLen, func(buf unsafe.Pointer) {
+		freed = append(freed, buf)
+	})
+	assert.NotNil(t, shinfo)
+	assert.Less(t, bufLen, uint16(mz.Len()))
+	assert.Equal(t, shinfo.RefCntRead(), uint16(1))
+
+	m := PktMbufAlloc(mp)
+	assert.NotNil(t, m)
+	m.AttachExtBuf(mz.Addr(), mz.IOVA(), bufLen, shinfo)
+	assert.Equal(t, m.ExtSharedInfo(), shinfo)
+	assert.Zero(t, m.PktLen())
+
+	// payload is already in place
+	assert.True(t, AppendMbuf(m, uint(len(sample))))
+	assert.Equal(t, m.Data(), sample)
+
+	// clone shares the external buffer
+	clone := m.PktMbufClone(mp)
+	assert.NotNil(t, clone)
+	assert.Equal(t, shinfo.RefCntRead(), uint16(2))
+	assert.Equal(t, clone.Data(), sample)
+
+	m.PktMbufFree()
+	assert.Empty(t, freed)
+	assert.Equal(t, shinfo.RefCntRead(), uint16(1))
+
+	clone.PktMbufFree()
+	assert.Equal(t, freed, []unsafe.Pointer{mz.Addr()})
+	assert.Zero(t, mp.InUseCount())
+
+	// buffer too small for the shared info
+	bufLen = 4
+	assert.Nil(t, ExtShinfoInitHelper(mz.Addr(), &bufLen, func(unsafe.Pointer) {}))
+}
+
+func TestIndirect(t *testing.T) {
+	eal.InitOnceSafe("test-mbuf", 1)
+
+	mp, err := mempool.CreateMbufPool("test-pool-indirect", 100, 1500)
+	assert.NoError(t, err)
+	defer mp.Free()
+
+	clonePool, err := mempool.CreateMbufPool("test-pool-indirect-clone", 100, 0)
+	assert.NoError(t, err)
+	defer clonePool.Free()
+
+	sample := getSample(100)
+	md := PktMbufAlloc(mp)
+	assert.NotNil(t, md)
+	assert.NoError(t, md.PktMbufAppend(sample))
+	assert.True(t, md.IsDirect())
+
+	mi := PktMbufAlloc(clonePool)
+	assert.NotNil(t, mi)
+	mi.Attach(md)
+	assert.True(t, mi.IsIndirect())
+	assert.False(t, mi.IsDirect())
+	assert.False(t, mi.IsExternal())
+	assert.Equal(t, mi.DirectMbuf(), md)
+	assert.Equal(t, mi.Data(), sample)
+	assert.Equal(t, md.RefCntRead(), uint16(2))
+
+	mi.Detach()
+	assert.True(t, mi.IsDirect())
+	assert.Equal(t, md.RefCntRead(), uint16(1))
+	mi.PktMbufFree()
+
+	// direct mbuf is freed along with the last indirect one
+	mi = PktMbufAlloc(clonePool)
+	mi.Attach(md)
+	md.PktMbufFree()
+	assert.Equal(t, mp.InUseCount(), 1)
+	mi.PktMbufFree()
+	assert.Zero(t, mp.InUseCount())
+	assert.Zero(t, clonePool.InUseCount())
+}
+
+func TestMulticast(t *testing.T) {
+	eal.InitOnceSafe("test-mbuf", 1)
+
+	mp, err := mempool.CreateMbufPool("test-pool-mcast", 100, 1500)
+	assert.NoError(t, err)
+	defer mp.Free()
+
+	hdrPool, err := mempool.CreateMbufPool("test-pool-mcast-hdr", 100, 128+64)
+	assert.NoError(t, err)
+	defer hdrPool.Free()
+
+	clonePool, err := mempool.CreateMbufPool("test-pool-mcast-clone", 100, 0)
+	assert.NoError(t, err)
+	defer clonePool.Free()
+
+	mz, err := memzone.Reserve("test-mz-mcast", 4096)
+	assert.NoError(t, err)
+	defer mz.Free()
+
+	sample := getSample(100)
+	copy(mz.Bytes(), sample)
+
+	// external payload reports exactly when its buffer is released
+	freed := 0
+	bufLen := uint16(mz.Len())
+	shinfo := ExtShinfoInitHelper(mz.Addr(), &bufLen, func(unsafe.Pointer) {
+		freed++
+	})
+	assert.NotNil(t, shinfo)
+
+	payload := PktMbufAlloc(mp)
+	assert.NotNil(t, payload)
+	payload.AttachExtBuf(mz.Addr(), mz.IOVA(), bufLen, shinfo)
+	assert.True(t, payload.IsExternal())
+	assert.True(t, AppendMbuf(payload, uint(len(sample))))
+
+	out := make([]*Mbuf, 4)
+	assert.NoError(t, Multicast(payload, hdrPool, clonePool, 14, out))
+	assert.Equal(t, shinfo.RefCntRead(), uint16(5))
+
+	buf := make([]byte, len(sample))
+	for i, pkt := range out {
+		copy(pkt.Data(), []byte{byte(i)})
+		assert.Equal(t, pkt.NbSegs(), uint16(2))
+		assert.Equal(t, pkt.PktLen(), uint32(14+len(sample)))
+		assert.True(t, pkt.IsDirect())
+		assert.True(t, pkt.Next().IsExternal())
+		_, err := pkt.ReadAt(buf, 14)
+		assert.NoError(t, err)
+		assert.Equal(t, buf, sample)
+	}
+
+	// headers are private to each packet
+	for i, pkt := range out {
+		assert.Equal(t, pkt.Data()[0], byte(i))
+	}
+
+	payload.PktMbufFree()
+	PktMbufFreeBulk(out[:3])
+	assert.Zero(t, freed)
+	assert.Equal(t, shinfo.RefCntRead(), uint16(1))
+
+	out[3].PktMbufFree()
+	assert.Equal(t, freed, 1)
+	assert.Zero(t, mp.InUseCount())
+	assert.Zero(t, hdrPool.InUseCount())
+	assert.Zero(t, clonePool.InUseCount())
+
+	// direct payload is freed with the last packet
+	payload = PktMbufAlloc(mp)
+	assert.NoError(t, payload.PktMbufAppend(sample))
+	assert.NoError(t, Multicast(payload, hdrPool, clonePool, 14, out))
+	assert.Equal(t, payload.RefCntRead(), uint16(5))
+	payload.PktMbufFree()
+	PktMbufFreeBulk(out[:3])
+	assert.Equal(t, mp.InUseCount(), 1)
+	out[3].PktMbufFree()
+	assert.Zero(t, mp.InUseCount())
+
+	// allocation failure rolls back
+	payload = PktMbufAlloc(mp)
+	assert.NoError(t, payload.PktMbufAppend(sample))
+	assert.ErrorIs(t, Multicast(payload, hdrPool, clonePool, 14, make([]*Mbuf, 200)), ErrNullData)
+	assert.Equal(t, payload.RefCntRead(), uint16(1))
+	assert.Zero(t, hdrPool.InUseCount())
+	assert.Zero(t, clonePool.InUseCount())
+	payload.PktMbufFree()
+}
+
+func TestCheckDump(t *testing.T) {
+	eal.InitOnceSafe("test-mbuf", 1)
+
+	mp, err := mempool.CreateMbufPool("test-pool-check", 100, 128+64)
+	assert.NoError(t, err)
+	defer mp.Free()
+
+	head := PktMbufAlloc(mp)
+	assert.NotNil(t, head)
+	assert.NoError(t, head.PktMbufAppend(getSample(64)))
+	tail := PktMbufAlloc(mp)
+	assert.NotNil(t, tail)
+	assert.NoError(t, tail.PktMbufAppend(getSample(32)))
+	assert.NoError(t, Chain(head, tail))
+	defer head.PktMbufFree()
+
+	assert.NoError(t, head.Check())
+	assert.NoError(t, tail.CheckSegment())
+
+	var sb strings.Builder
+	assert.NoError(t, head.Dump(&sb, 16))
+	assert.Contains(t, sb.String(), "pkt_len=96")
+	assert.Contains(t, sb.String(), "nb_segs=2")
+	assert.Contains(t, sb.String(), "segment at")
+
+	// pkt_len doesn't match the sum of data_len
+	head.pkt_len++
+	err = head.Check()
+	assert.ErrorIs(t, err, ErrBadMbuf)
+	assert.Contains(t, err.Error(), "pkt_len")
+	head.pkt_len--
+
+	// wrong number of segments
+	head.nb_segs = 3
+	err = head.Check()
+	assert.ErrorIs(t, err, ErrBadMbuf)
+	assert.Contains(t, err.Error(), "nb_segs")
+	head.nb_segs = 2
+
+	// bad reference counter
+	tail.RefCntSet(0)
+	err = head.Check()
+	assert.ErrorIs(t, err, ErrBadMbuf)
+	assert.Contains(t, err.Error(), "ref cnt")
+	tail.RefCntSet(1)
+
+	assert.NoError(t, head.Check())
+}
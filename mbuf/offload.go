@@ -0,0 +1,363 @@
+package mbuf
+
+/*
+#include <stddef.h>
+#include "offload.h"
+
+enum {
+	MBUF_TX_OFFLOAD_OFF = offsetof(struct rte_mbuf, tx_offload),
+};
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// OlFlags represents ol_flags field of an mbuf. It contains offload
+// features requested by the application for transmission or
+// reported by the driver upon reception.
+type OlFlags uint64
+
+// RX offload flags reported by the driver.
+const (
+	// RX packet is a 802.1q VLAN packet and the TCI has been saved
+	// in VlanTCI. If RxVlanStripped is also set, the VLAN header
+	// has been stripped from mbuf data, else it is still present.
+	RxVlan OlFlags = C.RTE_MBUF_F_RX_VLAN
+	// RX packet with RSS hash result stored in HashRss.
+	RxRssHash OlFlags = C.RTE_MBUF_F_RX_RSS_HASH
+	// RX packet with FDIR match indicate.
+	RxFdir OlFlags = C.RTE_MBUF_F_RX_FDIR
+	// A vlan has been stripped by the hardware and its TCI is saved
+	// in VlanTCI. This can only happen if vlan stripping is enabled
+	// in the RX configuration of the PMD. When RxVlanStripped is
+	// set, RxVlan must also be set.
+	RxVlanStripped OlFlags = C.RTE_MBUF_F_RX_VLAN_STRIPPED
+	// The outer IP checksum of a tunnelled packet is wrong.
+	RxOuterIPCksumBad OlFlags = C.RTE_MBUF_F_RX_OUTER_IP_CKSUM_BAD
+	// Mask of bits used to determine the status of RX IP checksum.
+	RxIPCksumMask OlFlags = C.RTE_MBUF_F_RX_IP_CKSUM_MASK
+	// No information about the RX IP checksum.
+	RxIPCksumUnknown OlFlags = C.RTE_MBUF_F_RX_IP_CKSUM_UNKNOWN
+	// The IP checksum in the packet is wrong.
+	RxIPCksumBad OlFlags = C.RTE_MBUF_F_RX_IP_CKSUM_BAD
+	// The IP checksum in the packet is valid.
+	RxIPCksumGood OlFlags = C.RTE_MBUF_F_RX_IP_CKSUM_GOOD
+	// The IP checksum is not correct in the packet data, but the
+	// integrity of the IP header is verified.
+	RxIPCksumNone OlFlags = C.RTE_MBUF_F_RX_IP_CKSUM_NONE
+	// Mask of bits used to determine the status of RX L4 checksum.
+	RxL4CksumMask OlFlags = C.RTE_MBUF_F_RX_L4_CKSUM_MASK
+	// No information about the RX L4 checksum.
+	RxL4CksumUnknown OlFlags = C.RTE_MBUF_F_RX_L4_CKSUM_UNKNOWN
+	// The L4 checksum in the packet is wrong.
+	RxL4CksumBad OlFlags = C.RTE_MBUF_F_RX_L4_CKSUM_BAD
+	// The L4 checksum in the packet is valid.
+	RxL4CksumGood OlFlags = C.RTE_MBUF_F_RX_L4_CKSUM_GOOD
+	// The L4 checksum is not correct in the packet data, but the
+	// integrity of the L4 data is verified.
+	RxL4CksumNone OlFlags = C.RTE_MBUF_F_RX_L4_CKSUM_NONE
+	// Mask of bits used to determine the status of outer RX L4
+	// checksum.
+	RxOuterL4CksumMask OlFlags = C.RTE_MBUF_F_RX_OUTER_L4_CKSUM_MASK
+	// The outer L4 checksum in the packet is wrong.
+	RxOuterL4CksumBad OlFlags = C.RTE_MBUF_F_RX_OUTER_L4_CKSUM_BAD
+	// The outer L4 checksum in the packet is valid.
+	RxOuterL4CksumGood OlFlags = C.RTE_MBUF_F_RX_OUTER_L4_CKSUM_GOOD
+	// RX packet is a double VLAN and the outer TCI has been saved in
+	// VlanTCIOuter.
+	RxQinQ OlFlags = C.RTE_MBUF_F_RX_QINQ
+	// Both VLANs have been stripped by the hardware.
+	RxQinQStripped OlFlags = C.RTE_MBUF_F_RX_QINQ_STRIPPED
+	// When packets are coalesced by a hardware or virtual driver,
+	// this flag can be set in the RX mbuf.
+	RxLRO OlFlags = C.RTE_MBUF_F_RX_LRO
+)
+
+// TX offload flags requested by the application.
+const (
+	// Outer UDP checksum offload flag. This flag is used for
+	// enabling outer UDP checksum in PMD.
+	TxOuterUDPCksum OlFlags = C.RTE_MBUF_F_TX_OUTER_UDP_CKSUM
+	// UDP Fragmentation Offload flag.
+	TxUDPSeg OlFlags = C.RTE_MBUF_F_TX_UDP_SEG
+	// Bits 45:48 are used for the tunnel type. The tunnel type must
+	// be specified for TSO or checksum on the inner part of tunnel
+	// packets.
+	TxTunnelVxlan     OlFlags = C.RTE_MBUF_F_TX_TUNNEL_VXLAN
+	TxTunnelGre       OlFlags = C.RTE_MBUF_F_TX_TUNNEL_GRE
+	TxTunnelIPIP      OlFlags = C.RTE_MBUF_F_TX_TUNNEL_IPIP
+	TxTunnelGeneve    OlFlags = C.RTE_MBUF_F_TX_TUNNEL_GENEVE
+	TxTunnelMplsInUDP OlFlags = C.RTE_MBUF_F_TX_TUNNEL_MPLSINUDP
+	TxTunnelVxlanGpe  OlFlags = C.RTE_MBUF_F_TX_TUNNEL_VXLAN_GPE
+	TxTunnelGtp       OlFlags = C.RTE_MBUF_F_TX_TUNNEL_GTP
+	TxTunnelMask      OlFlags = C.RTE_MBUF_F_TX_TUNNEL_MASK
+	// Second VLAN insertion (QinQ) flag.
+	TxQinQ OlFlags = C.RTE_MBUF_F_TX_QINQ
+	// TCP segmentation offload. To enable this offload feature for a
+	// packet to be transmitted on hardware supporting TSO TxTCPSeg
+	// should be set (it implies TxTCPCksum), TxIPv4 or TxIPv6 should
+	// be set and TxIPCksum should be set for IPv4. Also L2Len,
+	// L3Len, L4Len and TsoSegsz should be filled.
+	TxTCPSeg OlFlags = C.RTE_MBUF_F_TX_TCP_SEG
+	// Disable L4 cksum of TX pkt.
+	TxL4NoCksum OlFlags = C.RTE_MBUF_F_TX_L4_NO_CKSUM
+	// TCP cksum of TX pkt computed by NIC.
+	TxTCPCksum OlFlags = C.RTE_MBUF_F_TX_TCP_CKSUM
+	// SCTP cksum of TX pkt computed by NIC.
+	TxSCTPCksum OlFlags = C.RTE_MBUF_F_TX_SCTP_CKSUM
+	// UDP cksum of TX pkt computed by NIC.
+	TxUDPCksum OlFlags = C.RTE_MBUF_F_TX_UDP_CKSUM
+	// Mask for L4 cksum offload request.
+	TxL4Mask OlFlags = C.RTE_MBUF_F_TX_L4_MASK
+	// Offload the IP checksum in the hardware. The flag TxIPv4
+	// should also be set by the application, although a PMD will
+	// only check TxIPCksum.
+	TxIPCksum OlFlags = C.RTE_MBUF_F_TX_IP_CKSUM
+	// Packet is IPv4. This flag must be set when using any offload
+	// feature (TSO, L3 or L4 checksum) to tell the NIC that the
+	// packet is an IPv4 packet.
+	TxIPv4 OlFlags = C.RTE_MBUF_F_TX_IPV4
+	// Packet is IPv6. This flag must be set when using an offload
+	// feature (TSO or L4 checksum) to tell the NIC that the packet
+	// is an IPv6 packet.
+	TxIPv6 OlFlags = C.RTE_MBUF_F_TX_IPV6
+	// VLAN tag insertion request to driver, driver may offload the
+	// insertion based on the device capability.
+	TxVlan OlFlags = C.RTE_MBUF_F_TX_VLAN
+	// Offload the IP checksum of an external header in the hardware.
+	TxOuterIPCksum OlFlags = C.RTE_MBUF_F_TX_OUTER_IP_CKSUM
+	// Packet outer header is IPv4.
+	TxOuterIPv4 OlFlags = C.RTE_MBUF_F_TX_OUTER_IPV4
+	// Packet outer header is IPv6.
+	TxOuterIPv6 OlFlags = C.RTE_MBUF_F_TX_OUTER_IPV6
+	// Bitmask of all supported packet TX offload features flags,
+	// which can be set for packet.
+	TxOffloadMask OlFlags = C.RTE_MBUF_F_TX_OFFLOAD_MASK
+)
+
+// Has tests if all bits of flag are set in f.
+func (f OlFlags) Has(flag OlFlags) bool {
+	return f&flag == flag
+}
+
+// RxIPCksum returns the status of RX IP checksum, i.e. one of
+// RxIPCksum{Unknown,Bad,Good,None}.
+func (f OlFlags) RxIPCksum() OlFlags {
+	return f & RxIPCksumMask
+}
+
+// RxL4Cksum returns the status of RX L4 checksum, i.e. one of
+// RxL4Cksum{Unknown,Bad,Good,None}.
+func (f OlFlags) RxL4Cksum() OlFlags {
+	return f & RxL4CksumMask
+}
+
+// RxOuterL4Cksum returns the status of RX outer L4 checksum.
+func (f OlFlags) RxOuterL4Cksum() OlFlags {
+	return f & RxOuterL4CksumMask
+}
+
+// IPCksumGood tells if the driver verified IP checksum and found it
+// valid.
+func (f OlFlags) IPCksumGood() bool {
+	return f.RxIPCksum() == RxIPCksumGood
+}
+
+// IPCksumBad tells if the driver verified IP checksum and found it
+// wrong.
+func (f OlFlags) IPCksumBad() bool {
+	return f.RxIPCksum() == RxIPCksumBad
+}
+
+// L4CksumGood tells if the driver verified L4 checksum and found it
+// valid.
+func (f OlFlags) L4CksumGood() bool {
+	return f.RxL4Cksum() == RxL4CksumGood
+}
+
+// L4CksumBad tells if the driver verified L4 checksum and found it
+// wrong.
+func (f OlFlags) L4CksumBad() bool {
+	return f.RxL4Cksum() == RxL4CksumBad
+}
+
+// VlanStripped tells if VLAN tag was stripped by the hardware.
+func (f OlFlags) VlanStripped() bool {
+	return f.Has(RxVlanStripped)
+}
+
+// RssHashValid tells if HashRss contains a valid RSS hash.
+func (f OlFlags) RssHashValid() bool {
+	return f.Has(RxRssHash)
+}
+
+// TunnelType returns the tunnel type requested for TX offload, i.e.
+// one of TxTunnel* flags or 0.
+func (f OlFlags) TunnelType() OlFlags {
+	return f & TxTunnelMask
+}
+
+// OlFlags returns offload features of the mbuf.
+func (m *Mbuf) OlFlags() OlFlags {
+	return OlFlags(m.ol_flags)
+}
+
+// SetOlFlags overwrites offload features of the mbuf.
+func (m *Mbuf) SetOlFlags(f OlFlags) {
+	m.ol_flags = C.uint64_t(f)
+}
+
+// AddOlFlags sets specified offload features in the mbuf leaving
+// other flags intact.
+func (m *Mbuf) AddOlFlags(f OlFlags) {
+	m.ol_flags |= C.uint64_t(f)
+}
+
+// ClearOlFlags resets specified offload features in the mbuf leaving
+// other flags intact.
+func (m *Mbuf) ClearOlFlags(f OlFlags) {
+	m.ol_flags &^= C.uint64_t(f)
+}
+
+// Bit fields of tx_offload field of an mbuf.
+type txOffloadField struct {
+	ofs, bits uint
+}
+
+var (
+	txL2Len      = txOffloadField{C.RTE_MBUF_L2_LEN_OFS, C.RTE_MBUF_L2_LEN_BITS}
+	txL3Len      = txOffloadField{C.RTE_MBUF_L3_LEN_OFS, C.RTE_MBUF_L3_LEN_BITS}
+	txL4Len      = txOffloadField{C.RTE_MBUF_L4_LEN_OFS, C.RTE_MBUF_L4_LEN_BITS}
+	txTsoSegsz   = txOffloadField{C.RTE_MBUF_TSO_SEGSZ_OFS, C.RTE_MBUF_TSO_SEGSZ_BITS}
+	txOuterL3Len = txOffloadField{C.RTE_MBUF_OUTL3_LEN_OFS, C.RTE_MBUF_OUTL3_LEN_BITS}
+	txOuterL2Len = txOffloadField{C.RTE_MBUF_OUTL2_LEN_OFS, C.RTE_MBUF_OUTL2_LEN_BITS}
+)
+
+func (f txOffloadField) mask() uint64 {
+	return (1<<f.bits - 1) << f.ofs
+}
+
+func (m *Mbuf) txOffload() *uint64 {
+	return (*uint64)(unsafe.Add(unsafe.Pointer(m), C.MBUF_TX_OFFLOAD_OFF))
+}
+
+func (m *Mbuf) getTxOffload(f txOffloadField) uint16 {
+	return uint16((*m.txOffload() & f.mask()) >> f.ofs)
+}
+
+func (m *Mbuf) setTxOffload(f txOffloadField, v uint16) {
+	p := m.txOffload()
+	*p = *p&^f.mask() | (uint64(v)<<f.ofs)&f.mask()
+}
+
+// TxOffload returns tx_offload field of the mbuf which combines all
+// header lengths and TSO segment size.
+func (m *Mbuf) TxOffload() uint64 {
+	return *m.txOffload()
+}
+
+// SetTxOffload sets all header lengths and TSO segment size at once,
+// as in rte_mbuf_tx_offload. Values which don't fit into their
+// respective bit fields are truncated.
+func (m *Mbuf) SetTxOffload(l2, l3, l4, tsoSegsz, outerL3, outerL2 uint16) {
+	var v uint64
+	for _, x := range []struct {
+		f txOffloadField
+		v uint16
+	}{
+		{txL2Len, l2},
+		{txL3Len, l3},
+		{txL4Len, l4},
+		{txTsoSegsz, tsoSegsz},
+		{txOuterL3Len, outerL3},
+		{txOuterL2Len, outerL2},
+	} {
+		v |= (uint64(x.v) << x.f.ofs) & x.f.mask()
+	}
+	*m.txOffload() = v
+}
+
+// L2Len returns the length of L2 header. For tunnelled packets it
+// includes the outer L4 and tunnel headers as well as the inner L2
+// header.
+func (m *Mbuf) L2Len() uint16 {
+	return m.getTxOffload(txL2Len)
+}
+
+// SetL2Len sets the length of L2 header. The value is limited to 7
+// bits.
+func (m *Mbuf) SetL2Len(n uint16) {
+	m.setTxOffload(txL2Len, n)
+}
+
+// L3Len returns the length of L3 (IP) header.
+func (m *Mbuf) L3Len() uint16 {
+	return m.getTxOffload(txL3Len)
+}
+
+// SetL3Len sets the length of L3 (IP) header. The value is limited
+// to 9 bits.
+func (m *Mbuf) SetL3Len(n uint16) {
+	m.setTxOffload(txL3Len, n)
+}
+
+// L4Len returns the length of L4 (TCP/UDP) header.
+func (m *Mbuf) L4Len() uint16 {
+	return m.getTxOffload(txL4Len)
+}
+
+// SetL4Len sets the length of L4 header. The value is limited to 8
+// bits.
+func (m *Mbuf) SetL4Len(n uint16) {
+	m.setTxOffload(txL4Len, n)
+}
+
+// TsoSegsz returns TCP TSO segment size.
+func (m *Mbuf) TsoSegsz() uint16 {
+	return m.getTxOffload(txTsoSegsz)
+}
+
+// SetTsoSegsz sets TCP TSO segment size. It is used only if TxTCPSeg
+// or TxUDPSeg flag is set.
+func (m *Mbuf) SetTsoSegsz(n uint16) {
+	m.setTxOffload(txTsoSegsz, n)
+}
+
+// OuterL2Len returns the length of outer L2 header of a tunnelled
+// packet.
+func (m *Mbuf) OuterL2Len() uint16 {
+	return m.getTxOffload(txOuterL2Len)
+}
+
+// SetOuterL2Len sets the length of outer L2 header of a tunnelled
+// packet. The value is limited to 7 bits.
+func (m *Mbuf) SetOuterL2Len(n uint16) {
+	m.setTxOffload(txOuterL2Len, n)
+}
+
+// OuterL3Len returns the length of outer L3 header of a tunnelled
+// packet.
+func (m *Mbuf) OuterL3Len() uint16 {
+	return m.getTxOffload(txOuterL3Len)
+}
+
+// SetOuterL3Len sets the length of outer L3 header of a tunnelled
+// packet. The value is limited to 9 bits.
+func (m *Mbuf) SetOuterL3Len(n uint16) {
+	m.setTxOffload(txOuterL3Len, n)
+}
+
+// VlanTCI returns VLAN TCI (CPU order) which is valid if RxVlan flag
+// is set on receive or is used for VLAN insertion if TxVlan flag is
+// set on transmit.
+func (m *Mbuf) VlanTCI() uint16 {
+	return uint16(m.vlan_tci)
+}
+
+// SetVlanTCI sets VLAN TCI (CPU order) to insert if TxVlan flag is
+// set.
+func (m *Mbuf) SetVlanTCI(tci uint16) {
+	m.vlan_tci = C.uint16_t(tci)
+}
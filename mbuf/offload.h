@@ -0,0 +1,61 @@
+#ifndef _OFFLOAD_H_
+#define _OFFLOAD_H_
+
+#include <rte_config.h>
+#include <rte_version.h>
+#include <rte_mbuf.h>
+
+/* offload flags were renamed in DPDK 21.11 */
+#if RTE_VERSION < RTE_VERSION_NUM(21, 11, 0, 0)
+#define RTE_MBUF_F_RX_FDIR                PKT_RX_FDIR
+#define RTE_MBUF_F_RX_IP_CKSUM_BAD        PKT_RX_IP_CKSUM_BAD
+#define RTE_MBUF_F_RX_IP_CKSUM_GOOD       PKT_RX_IP_CKSUM_GOOD
+#define RTE_MBUF_F_RX_IP_CKSUM_MASK       PKT_RX_IP_CKSUM_MASK
+#define RTE_MBUF_F_RX_IP_CKSUM_NONE       PKT_RX_IP_CKSUM_NONE
+#define RTE_MBUF_F_RX_IP_CKSUM_UNKNOWN    PKT_RX_IP_CKSUM_UNKNOWN
+#define RTE_MBUF_F_RX_L4_CKSUM_BAD        PKT_RX_L4_CKSUM_BAD
+#define RTE_MBUF_F_RX_L4_CKSUM_GOOD       PKT_RX_L4_CKSUM_GOOD
+#define RTE_MBUF_F_RX_L4_CKSUM_MASK       PKT_RX_L4_CKSUM_MASK
+#define RTE_MBUF_F_RX_L4_CKSUM_NONE       PKT_RX_L4_CKSUM_NONE
+#define RTE_MBUF_F_RX_L4_CKSUM_UNKNOWN    PKT_RX_L4_CKSUM_UNKNOWN
+#define RTE_MBUF_F_RX_LRO                 PKT_RX_LRO
+#define RTE_MBUF_F_RX_OUTER_IP_CKSUM_BAD  PKT_RX_OUTER_IP_CKSUM_BAD
+#define RTE_MBUF_F_RX_OUTER_L4_CKSUM_BAD  PKT_RX_OUTER_L4_CKSUM_BAD
+#define RTE_MBUF_F_RX_OUTER_L4_CKSUM_GOOD PKT_RX_OUTER_L4_CKSUM_GOOD
+#define RTE_MBUF_F_RX_OUTER_L4_CKSUM_MASK PKT_RX_OUTER_L4_CKSUM_MASK
+#define RTE_MBUF_F_RX_QINQ                PKT_RX_QINQ
+#define RTE_MBUF_F_RX_QINQ_STRIPPED       PKT_RX_QINQ_STRIPPED
+#define RTE_MBUF_F_RX_RSS_HASH            PKT_RX_RSS_HASH
+#define RTE_MBUF_F_RX_VLAN                PKT_RX_VLAN
+#define RTE_MBUF_F_RX_VLAN_STRIPPED       PKT_RX_VLAN_STRIPPED
+#define RTE_MBUF_F_TX_IPV4                PKT_TX_IPV4
+#define RTE_MBUF_F_TX_IPV6                PKT_TX_IPV6
+#define RTE_MBUF_F_TX_IP_CKSUM            PKT_TX_IP_CKSUM
+#define RTE_MBUF_F_TX_L4_MASK             PKT_TX_L4_MASK
+#define RTE_MBUF_F_TX_L4_NO_CKSUM         PKT_TX_L4_NO_CKSUM
+#define RTE_MBUF_F_TX_OFFLOAD_MASK        PKT_TX_OFFLOAD_MASK
+#define RTE_MBUF_F_TX_OUTER_IPV4          PKT_TX_OUTER_IPV4
+#define RTE_MBUF_F_TX_OUTER_IPV6          PKT_TX_OUTER_IPV6
+#define RTE_MBUF_F_TX_OUTER_IP_CKSUM      PKT_TX_OUTER_IP_CKSUM
+#define RTE_MBUF_F_TX_OUTER_UDP_CKSUM     PKT_TX_OUTER_UDP_CKSUM
+#define RTE_MBUF_F_TX_QINQ                PKT_TX_QINQ
+#define RTE_MBUF_F_TX_SCTP_CKSUM          PKT_TX_SCTP_CKSUM
+#define RTE_MBUF_F_TX_TCP_CKSUM           PKT_TX_TCP_CKSUM
+#define RTE_MBUF_F_TX_TCP_SEG             PKT_TX_TCP_SEG
+#define RTE_MBUF_F_TX_TUNNEL_GENEVE       PKT_TX_TUNNEL_GENEVE
+#define RTE_MBUF_F_TX_TUNNEL_GRE          PKT_TX_TUNNEL_GRE
+#define RTE_MBUF_F_TX_TUNNEL_GTP          PKT_TX_TUNNEL_GTP
+#define RTE_MBUF_F_TX_TUNNEL_IPIP         PKT_TX_TUNNEL_IPIP
+#define RTE_MBUF_F_TX_TUNNEL_MASK         PKT_TX_TUNNEL_MASK
+#define RTE_MBUF_F_TX_TUNNEL_MPLSINUDP    PKT_TX_TUNNEL_MPLSINUDP
+#define RTE_MBUF_F_TX_TUNNEL_VXLAN        PKT_TX_TUNNEL_VXLAN
+#define RTE_MBUF_F_TX_TUNNEL_VXLAN_GPE    PKT_TX_TUNNEL_VXLAN_GPE
+#define RTE_MBUF_F_TX_UDP_CKSUM           PKT_TX_UDP_CKSUM
+#define RTE_MBUF_F_TX_UDP_SEG             PKT_TX_UDP_SEG
+#define RTE_MBUF_F_TX_VLAN                PKT_TX_VLAN
+
+#define RTE_MBUF_F_INDIRECT               IND_ATTACHED_MBUF
+#define RTE_MBUF_F_EXTERNAL               EXT_ATTACHED_MBUF
+#endif
+
+#endif /* _OFFLOAD_H_ */
@@ -0,0 +1,275 @@
+package mbuf
+
+/*
+#include <stddef.h>
+#include <rte_config.h>
+#include <rte_mbuf.h>
+#include <rte_mbuf_ptype.h>
+#include <rte_net.h>
+
+enum {
+	MBUF_PTYPE_OFF = offsetof(struct rte_mbuf, packet_type),
+};
+*/
+import "C"
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// PacketType describes the packet headers recognized by the driver
+// or by software classification. It is a bit field made of layer
+// values which are mutually exclusive within their masks, i.e. a
+// packet may not be both PtypeL3IPv4 and PtypeL3IPv6.
+type PacketType uint32
+
+// Unknown packet type.
+const (
+	PtypeUnknown PacketType = C.RTE_PTYPE_UNKNOWN
+)
+
+// Layer 2 packet types.
+const (
+	PtypeL2Ether         PacketType = C.RTE_PTYPE_L2_ETHER
+	PtypeL2EtherTimesync PacketType = C.RTE_PTYPE_L2_ETHER_TIMESYNC
+	PtypeL2EtherARP      PacketType = C.RTE_PTYPE_L2_ETHER_ARP
+	PtypeL2EtherLLDP     PacketType = C.RTE_PTYPE_L2_ETHER_LLDP
+	PtypeL2EtherNSH      PacketType = C.RTE_PTYPE_L2_ETHER_NSH
+	PtypeL2EtherVlan     PacketType = C.RTE_PTYPE_L2_ETHER_VLAN
+	PtypeL2EtherQinQ     PacketType = C.RTE_PTYPE_L2_ETHER_QINQ
+	PtypeL2EtherPPPoE    PacketType = C.RTE_PTYPE_L2_ETHER_PPPOE
+	PtypeL2EtherFCoE     PacketType = C.RTE_PTYPE_L2_ETHER_FCOE
+	PtypeL2EtherMPLS     PacketType = C.RTE_PTYPE_L2_ETHER_MPLS
+	PtypeL2Mask          PacketType = C.RTE_PTYPE_L2_MASK
+)
+
+// Layer 3 packet types.
+const (
+	// IPv4 header without options.
+	PtypeL3IPv4 PacketType = C.RTE_PTYPE_L3_IPV4
+	// IPv4 header with options.
+	PtypeL3IPv4Ext PacketType = C.RTE_PTYPE_L3_IPV4_EXT
+	// IPv6 header without extension headers.
+	PtypeL3IPv6 PacketType = C.RTE_PTYPE_L3_IPV6
+	// IPv4 header which may or may not contain options.
+	PtypeL3IPv4ExtUnknown PacketType = C.RTE_PTYPE_L3_IPV4_EXT_UNKNOWN
+	// IPv6 header with extension headers.
+	PtypeL3IPv6Ext PacketType = C.RTE_PTYPE_L3_IPV6_EXT
+	// IPv6 header which may or may not contain extension headers.
+	PtypeL3IPv6ExtUnknown PacketType = C.RTE_PTYPE_L3_IPV6_EXT_UNKNOWN
+	PtypeL3Mask           PacketType = C.RTE_PTYPE_L3_MASK
+)
+
+// Layer 4 packet types.
+const (
+	PtypeL4TCP     PacketType = C.RTE_PTYPE_L4_TCP
+	PtypeL4UDP     PacketType = C.RTE_PTYPE_L4_UDP
+	PtypeL4Frag    PacketType = C.RTE_PTYPE_L4_FRAG
+	PtypeL4SCTP    PacketType = C.RTE_PTYPE_L4_SCTP
+	PtypeL4ICMP    PacketType = C.RTE_PTYPE_L4_ICMP
+	PtypeL4NonFrag PacketType = C.RTE_PTYPE_L4_NONFRAG
+	PtypeL4Mask    PacketType = C.RTE_PTYPE_L4_MASK
+)
+
+// Tunnel packet types.
+const (
+	PtypeTunnelIP        PacketType = C.RTE_PTYPE_TUNNEL_IP
+	PtypeTunnelGRE       PacketType = C.RTE_PTYPE_TUNNEL_GRE
+	PtypeTunnelVxlan     PacketType = C.RTE_PTYPE_TUNNEL_VXLAN
+	PtypeTunnelNVGRE     PacketType = C.RTE_PTYPE_TUNNEL_NVGRE
+	PtypeTunnelGeneve    PacketType = C.RTE_PTYPE_TUNNEL_GENEVE
+	PtypeTunnelGRENAT    PacketType = C.RTE_PTYPE_TUNNEL_GRENAT
+	PtypeTunnelGTPC      PacketType = C.RTE_PTYPE_TUNNEL_GTPC
+	PtypeTunnelGTPU      PacketType = C.RTE_PTYPE_TUNNEL_GTPU
+	PtypeTunnelESP       PacketType = C.RTE_PTYPE_TUNNEL_ESP
+	PtypeTunnelL2TP      PacketType = C.RTE_PTYPE_TUNNEL_L2TP
+	PtypeTunnelVxlanGPE  PacketType = C.RTE_PTYPE_TUNNEL_VXLAN_GPE
+	PtypeTunnelMPLSInGRE PacketType = C.RTE_PTYPE_TUNNEL_MPLS_IN_GRE
+	PtypeTunnelMPLSInUDP PacketType = C.RTE_PTYPE_TUNNEL_MPLS_IN_UDP
+	PtypeTunnelMask      PacketType = C.RTE_PTYPE_TUNNEL_MASK
+)
+
+// Inner layer 2 packet types.
+const (
+	PtypeInnerL2Ether     PacketType = C.RTE_PTYPE_INNER_L2_ETHER
+	PtypeInnerL2EtherVlan PacketType = C.RTE_PTYPE_INNER_L2_ETHER_VLAN
+	PtypeInnerL2EtherQinQ PacketType = C.RTE_PTYPE_INNER_L2_ETHER_QINQ
+	PtypeInnerL2Mask      PacketType = C.RTE_PTYPE_INNER_L2_MASK
+)
+
+// Inner layer 3 packet types.
+const (
+	PtypeInnerL3IPv4           PacketType = C.RTE_PTYPE_INNER_L3_IPV4
+	PtypeInnerL3IPv4Ext        PacketType = C.RTE_PTYPE_INNER_L3_IPV4_EXT
+	PtypeInnerL3IPv6           PacketType = C.RTE_PTYPE_INNER_L3_IPV6
+	PtypeInnerL3IPv4ExtUnknown PacketType = C.RTE_PTYPE_INNER_L3_IPV4_EXT_UNKNOWN
+	PtypeInnerL3IPv6Ext        PacketType = C.RTE_PTYPE_INNER_L3_IPV6_EXT
+	PtypeInnerL3IPv6ExtUnknown PacketType = C.RTE_PTYPE_INNER_L3_IPV6_EXT_UNKNOWN
+	PtypeInnerL3Mask           PacketType = C.RTE_PTYPE_INNER_L3_MASK
+)
+
+// Inner layer 4 packet types.
+const (
+	PtypeInnerL4TCP     PacketType = C.RTE_PTYPE_INNER_L4_TCP
+	PtypeInnerL4UDP     PacketType = C.RTE_PTYPE_INNER_L4_UDP
+	PtypeInnerL4Frag    PacketType = C.RTE_PTYPE_INNER_L4_FRAG
+	PtypeInnerL4SCTP    PacketType = C.RTE_PTYPE_INNER_L4_SCTP
+	PtypeInnerL4ICMP    PacketType = C.RTE_PTYPE_INNER_L4_ICMP
+	PtypeInnerL4NonFrag PacketType = C.RTE_PTYPE_INNER_L4_NONFRAG
+	PtypeInnerL4Mask    PacketType = C.RTE_PTYPE_INNER_L4_MASK
+)
+
+// PtypeAllMask contains all layer masks.
+const PtypeAllMask PacketType = C.RTE_PTYPE_ALL_MASK
+
+// L2 returns layer 2 packet type.
+func (p PacketType) L2() PacketType {
+	return p & PtypeL2Mask
+}
+
+// L3 returns layer 3 packet type.
+func (p PacketType) L3() PacketType {
+	return p & PtypeL3Mask
+}
+
+// L4 returns layer 4 packet type.
+func (p PacketType) L4() PacketType {
+	return p & PtypeL4Mask
+}
+
+// Tunnel returns tunnel packet type.
+func (p PacketType) Tunnel() PacketType {
+	return p & PtypeTunnelMask
+}
+
+// InnerL2 returns inner layer 2 packet type.
+func (p PacketType) InnerL2() PacketType {
+	return p & PtypeInnerL2Mask
+}
+
+// InnerL3 returns inner layer 3 packet type.
+func (p PacketType) InnerL3() PacketType {
+	return p & PtypeInnerL3Mask
+}
+
+// InnerL4 returns inner layer 4 packet type.
+func (p PacketType) InnerL4() PacketType {
+	return p & PtypeInnerL4Mask
+}
+
+// IsIPv4 tells if the packet has IPv4 header of any kind, as in
+// RTE_ETH_IS_IPV4_HDR.
+func (p PacketType) IsIPv4() bool {
+	return p&PtypeL3IPv4 != 0
+}
+
+// IsIPv6 tells if the packet has IPv6 header of any kind, as in
+// RTE_ETH_IS_IPV6_HDR.
+func (p PacketType) IsIPv6() bool {
+	return p&PtypeL3IPv6 != 0
+}
+
+// IsTunnel tells if the packet is a tunnel packet, as in
+// RTE_ETH_IS_TUNNEL_PKT.
+func (p PacketType) IsTunnel() bool {
+	return p&(PtypeTunnelMask|PtypeInnerL2Mask|PtypeInnerL3Mask|PtypeInnerL4Mask) != 0
+}
+
+// String implements fmt.Stringer. Names of all layers are separated
+// by space, e.g. "L2_ETHER L3_IPV4 L4_UDP".
+func (p PacketType) String() string {
+	var buf [256]C.char
+	if C.rte_get_ptype_name(C.uint32_t(p), &buf[0], C.size_t(len(buf))) < 0 {
+		return "UNKNOWN"
+	}
+	// every layer name is followed by a space
+	return strings.TrimRight(C.GoString(&buf[0]), " ")
+}
+
+func (m *Mbuf) ptype() *PacketType {
+	return (*PacketType)(unsafe.Add(unsafe.Pointer(m), C.MBUF_PTYPE_OFF))
+}
+
+// PacketType returns packet type of the mbuf as reported by the
+// driver or stored by SetPacketType. PtypeUnknown means the packet
+// wasn't classified.
+func (m *Mbuf) PacketType() PacketType {
+	return *m.ptype()
+}
+
+// SetPacketType stores packet type in the mbuf.
+func (m *Mbuf) SetPacketType(p PacketType) {
+	*m.ptype() = p
+}
+
+// HdrLens contains the lengths of packet headers found by
+// NetGetPtype. Mirrors struct rte_net_hdr_lens.
+type HdrLens struct {
+	L2Len      uint8
+	InnerL2Len uint8
+	L3Len      uint16
+	InnerL3Len uint16
+	TunnelLen  uint16
+	L4Len      uint8
+	InnerL4Len uint8
+}
+
+// NetGetPtype parses the packet headers in software and returns its
+// packet type. This function doesn't rely on the packet type of the
+// mbuf and the result is not stored in it.
+//
+// layers is a mask of layers to parse, e.g. PtypeL2Mask|PtypeL3Mask
+// stops parsing after L3 header. Use PtypeAllMask to parse
+// everything known to DPDK.
+//
+// If lens is not nil, it is filled with the lengths of parsed
+// headers.
+func (m *Mbuf) NetGetPtype(layers PacketType, lens *HdrLens) PacketType {
+	var hl C.struct_rte_net_hdr_lens
+	p := PacketType(C.rte_net_get_ptype(ToCMbuf(m), &hl, C.uint32_t(layers)))
+	if lens != nil {
+		*lens = HdrLens{
+			L2Len:      uint8(hl.l2_len),
+			InnerL2Len: uint8(hl.inner_l2_len),
+			L3Len:      uint16(hl.l3_len),
+			InnerL3Len: uint16(hl.inner_l3_len),
+			TunnelLen:  uint16(hl.tunnel_len),
+			L4Len:      uint8(hl.l4_len),
+			InnerL4Len: uint8(hl.inner_l4_len),
+		}
+	}
+	return p
+}
+
+// Classify returns packet type of the mbuf. If the driver has already
+// classified the packet its packet type is returned as is.
+//
+// Otherwise, the packet is parsed in software with NetGetPtype, the
+// resulting packet type is stored in the mbuf and header lengths are
+// stored as L2Len, L3Len, L4Len (and OuterL2Len, OuterL3Len for
+// tunnelled packets) so that subsequent calls and TX offloads don't
+// need to parse the packet again.
+func (m *Mbuf) Classify() PacketType {
+	if p := m.PacketType(); p != PtypeUnknown {
+		return p
+	}
+
+	var lens HdrLens
+	p := m.NetGetPtype(PtypeAllMask, &lens)
+	m.SetPacketType(p)
+
+	if p.IsTunnel() {
+		m.SetOuterL2Len(uint16(lens.L2Len))
+		m.SetOuterL3Len(lens.L3Len)
+		m.SetL2Len(uint16(lens.L4Len) + lens.TunnelLen + uint16(lens.InnerL2Len))
+		m.SetL3Len(lens.InnerL3Len)
+		m.SetL4Len(uint16(lens.InnerL4Len))
+	} else {
+		m.SetL2Len(uint16(lens.L2Len))
+		m.SetL3Len(lens.L3Len)
+		m.SetL4Len(uint16(lens.L4Len))
+	}
+
+	return p
+}
@@ -0,0 +1,194 @@
+//go:build mbuftrack
+// +build mbuftrack
+
+package mbuf
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TrackEnabled tells if mbuf leak tracking is compiled in. Build with
+// 'mbuftrack' tag to enable it.
+const TrackEnabled = true
+
+// maximum depth of recorded call stack.
+const trackDepth = 32
+
+var tracker = struct {
+	sync.Mutex
+	mbufs map[*Mbuf][]uintptr
+}{mbufs: make(map[*Mbuf][]uintptr)}
+
+// trackAlloc records the call site of the function which allocated
+// mbufs. skip is the number of frames to skip above the caller of
+// trackAlloc.
+func trackAlloc(skip int, ms ...*Mbuf) {
+	pcs := make([]uintptr, trackDepth)
+	pcs = pcs[:runtime.Callers(skip+2, pcs)]
+
+	tracker.Lock()
+	for _, m := range ms {
+		if m != nil {
+			tracker.mbufs[m] = pcs
+		}
+	}
+	tracker.Unlock()
+}
+
+type trackRecord struct {
+	m   *Mbuf
+	pcs []uintptr
+}
+
+// trackForget forgets packet m along with all its segments and
+// appends forgotten records to recs. A segment is forgotten only if
+// it's the last reference to the mbuf, otherwise the free merely
+// decrements its reference counter. If the segment is a clone, its
+// direct mbuf is forgotten as well in case the clone is the last
+// reference to it. Must be called with tracker locked.
+func trackForget(m *Mbuf, recs []trackRecord) []trackRecord {
+	forget := func(m *Mbuf) {
+		if pcs, ok := tracker.mbufs[m]; ok {
+			recs = append(recs, trackRecord{m, pcs})
+			delete(tracker.mbufs, m)
+		}
+	}
+
+	for seg := m; seg != nil; seg = seg.Next() {
+		if seg.RefCntRead() != 1 {
+			continue
+		}
+		forget(seg)
+		if seg.IsIndirect() {
+			if md := seg.DirectMbuf(); md.RefCntRead() == 1 {
+				forget(md)
+			}
+		}
+	}
+	return recs
+}
+
+// trackFree forgets the packets along with all their segments the
+// same way as PktMbufFree releases them.
+func trackFree(ms ...*Mbuf) {
+	tracker.Lock()
+	for _, m := range ms {
+		trackForget(m, nil)
+	}
+	tracker.Unlock()
+}
+
+// Untrack forgets packets which ownership is handed over to DPDK,
+// e.g. when they are freed by a PMD on its own. Reference counters
+// are respected the same way as in PktMbufFree. The packets must not
+// be accessed after the handover, so Untrack must be called before
+// it.
+//
+// Other code which passes mbufs to DPDK for freeing should call it
+// as well to avoid false leak reports.
+func Untrack(ms ...*Mbuf) {
+	trackFree(ms...)
+}
+
+// Handover forgets packets which are about to be handed over to
+// DPDK and returns a function to be called with the number of
+// packets DPDK actually took. The function restores records of the
+// rest of the packets since they are still owned by the caller.
+//
+// Port.TxBurst in ethdev uses it since a PMD may send only a part of
+// the burst and sent packets may be freed by the time it returns.
+func Handover(ms []*Mbuf) (taken func(n int)) {
+	recs := make([][]trackRecord, len(ms))
+	tracker.Lock()
+	for i, m := range ms {
+		recs[i] = trackForget(m, nil)
+	}
+	tracker.Unlock()
+
+	return func(n int) {
+		tracker.Lock()
+		for _, rs := range recs[n:] {
+			for _, r := range rs {
+				tracker.mbufs[r.m] = r.pcs
+			}
+		}
+		tracker.Unlock()
+	}
+}
+
+// trackSegs forgets specific segments without walking the chain.
+func trackSegs(segs []*Mbuf) {
+	tracker.Lock()
+	for _, seg := range segs {
+		delete(tracker.mbufs, seg)
+	}
+	tracker.Unlock()
+}
+
+// Outstanding returns mbufs which are allocated but not freed yet
+// grouped by the call stack of allocation. Groups are sorted by the
+// number of mbufs in descending order.
+func Outstanding() []Leak {
+	groups := make(map[string]*Leak)
+
+	tracker.Lock()
+	for m, pcs := range tracker.mbufs {
+		stack := formatStack(pcs)
+		l, ok := groups[stack]
+		if !ok {
+			l = &Leak{Stack: stack}
+			groups[stack] = l
+		}
+		l.Mbufs = append(l.Mbufs, m)
+	}
+	tracker.Unlock()
+
+	leaks := make([]Leak, 0, len(groups))
+	for _, l := range groups {
+		leaks = append(leaks, *l)
+	}
+
+	sort.Slice(leaks, func(i, j int) bool {
+		if len(leaks[i].Mbufs) != len(leaks[j].Mbufs) {
+			return len(leaks[i].Mbufs) > len(leaks[j].Mbufs)
+		}
+		return leaks[i].Stack < leaks[j].Stack
+	})
+	return leaks
+}
+
+// ResetTracking forgets all outstanding mbufs.
+func ResetTracking() {
+	tracker.Lock()
+	tracker.mbufs = make(map[*Mbuf][]uintptr)
+	tracker.Unlock()
+}
+
+func formatStack(pcs []uintptr) string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// ReportLeaks writes outstanding mbufs grouped by the call stack of
+// allocation into w. Returns the number of outstanding mbufs.
+func ReportLeaks(w io.Writer) int {
+	n := 0
+	for _, l := range Outstanding() {
+		n += len(l.Mbufs)
+		fmt.Fprintf(w, "%d mbuf(s) allocated at:\n%s\n", len(l.Mbufs), l.Stack)
+	}
+	return n
+}
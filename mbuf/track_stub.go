@@ -0,0 +1,45 @@
+//go:build !mbuftrack
+// +build !mbuftrack
+
+package mbuf
+
+import (
+	"io"
+)
+
+// TrackEnabled tells if mbuf leak tracking is compiled in. Build with
+// 'mbuftrack' tag to enable it.
+const TrackEnabled = false
+
+func trackAlloc(skip int, ms ...*Mbuf) {}
+
+func trackFree(ms ...*Mbuf) {}
+
+func trackSegs(segs []*Mbuf) {}
+
+// Untrack forgets packets which ownership is handed over to DPDK. It
+// does nothing unless built with 'mbuftrack' tag.
+func Untrack(ms ...*Mbuf) {}
+
+// Handover forgets packets which are about to be handed over to
+// DPDK. It does nothing unless built with 'mbuftrack' tag.
+func Handover(ms []*Mbuf) (taken func(n int)) {
+	return func(int) {}
+}
+
+// Outstanding returns mbufs which are allocated but not freed yet
+// grouped by the call stack of allocation. It always returns nil
+// unless built with 'mbuftrack' tag.
+func Outstanding() []Leak {
+	return nil
+}
+
+// ResetTracking forgets all outstanding mbufs.
+func ResetTracking() {}
+
+// ReportLeaks writes outstanding mbufs grouped by the call stack of
+// allocation into w. Returns the number of outstanding mbufs which is
+// always 0 unless built with 'mbuftrack' tag.
+func ReportLeaks(w io.Writer) int {
+	return 0
+}
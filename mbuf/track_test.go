@@ -0,0 +1,119 @@
+//go:build mbuftrack
+// +build mbuftrack
+
+package mbuf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tianyuansun/go-dpdk/eal"
+	"github.com/tianyuansun/go-dpdk/mempool"
+)
+
+func leakOne(p *mempool.Mempool) *Mbuf {
+	return PktMbufAlloc(p)
+}
+
+func leakBulk(p *mempool.Mempool, n int) []*Mbuf {
+	ms := make([]*Mbuf, n)
+	if err := PktMbufAllocBulk(p, ms); err != nil {
+		return nil
+	}
+	return ms
+}
+
+func TestTrackLeaks(t *testing.T) {
+	eal.InitOnceSafe("test-mbuf", 1)
+	ResetTracking()
+	defer ResetTracking()
+
+	mp, err := mempool.CreateMbufPool("test-pool-track", 100, 128+64)
+	assert.NoError(t, err)
+	defer mp.Free()
+
+	assert.True(t, TrackEnabled)
+	assert.Empty(t, Outstanding())
+
+	// deliberately leak mbufs from two call sites
+	bulk := leakBulk(mp, 3)
+	assert.NotNil(t, bulk)
+	one := leakOne(mp)
+	assert.NotNil(t, one)
+
+	leaks := Outstanding()
+	assert.Len(t, leaks, 2)
+	assert.Len(t, leaks[0].Mbufs, 3)
+	assert.Contains(t, leaks[0].Stack, "mbuf.leakBulk")
+	assert.NotContains(t, leaks[0].Stack, "mbuf.PktMbufAllocBulk")
+	assert.ElementsMatch(t, leaks[0].Mbufs, bulk)
+	assert.Len(t, leaks[1].Mbufs, 1)
+	assert.Contains(t, leaks[1].Stack, "mbuf.leakOne")
+	assert.Equal(t, leaks[1].Mbufs[0], one)
+
+	var sb strings.Builder
+	assert.Equal(t, ReportLeaks(&sb), 4)
+	assert.Contains(t, sb.String(), "3 mbuf(s) allocated at:")
+	assert.Contains(t, sb.String(), "1 mbuf(s) allocated at:")
+	assert.Equal(t, mp.InUseCount(), 4)
+
+	// clone is tracked on its own
+	clone := one.PktMbufClone(mp)
+	assert.NotNil(t, clone)
+	assert.Equal(t, ReportLeaks(&sb), 5)
+	clone.PktMbufFree()
+
+	// freeing a chained packet releases all of its segments
+	for _, m := range bulk {
+		assert.NoError(t, Chain(one, m))
+	}
+	assert.Equal(t, ReportLeaks(&sb), 4)
+	one.PktMbufFree()
+	assert.Empty(t, Outstanding())
+	assert.Zero(t, mp.InUseCount())
+
+	// bulk free
+	bulk = leakBulk(mp, 10)
+	assert.Len(t, Outstanding(), 1)
+	PktMbufFreeBulk(bulk)
+	assert.Empty(t, Outstanding())
+	assert.Zero(t, mp.InUseCount())
+
+	// shared mbuf is forgotten on the last free only
+	one = leakOne(mp)
+	one.RefCntUpdate(1)
+	one.PktMbufFree()
+	assert.Len(t, Outstanding(), 1)
+	one.PktMbufFree()
+	assert.Empty(t, Outstanding())
+
+	// direct mbuf is forgotten when its last clone is freed
+	one = leakOne(mp)
+	clone = one.PktMbufClone(mp)
+	assert.NotNil(t, clone)
+	one.PktMbufFree()
+	assert.Equal(t, ReportLeaks(&sb), 2)
+	clone.PktMbufFree()
+	assert.Empty(t, Outstanding())
+	assert.Zero(t, mp.InUseCount())
+
+	// mbufs handed over to DPDK
+	one = leakOne(mp)
+	Untrack(one)
+	assert.Empty(t, Outstanding())
+	one.PktMbufFree()
+
+	// packets not taken by DPDK are tracked again
+	bulk = leakBulk(mp, 4)
+	taken := Handover(bulk)
+	assert.Empty(t, Outstanding())
+	taken(1)
+	leaks = Outstanding()
+	assert.Len(t, leaks, 1)
+	assert.Contains(t, leaks[0].Stack, "mbuf.leakBulk")
+	assert.ElementsMatch(t, leaks[0].Mbufs, bulk[1:])
+	PktMbufFreeBulk(bulk)
+	assert.Empty(t, Outstanding())
+	assert.Zero(t, mp.InUseCount())
+}
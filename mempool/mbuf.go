@@ -52,3 +52,55 @@ func CreateMbufPool(name string, n uint32, dataRoomSize uint16, opts ...Option)
 
 	return mp, nil
 }
+
+// ExtMem describes external memory area used for data buffers of
+// mbufs created with CreateMbufPoolExtBuf.
+type ExtMem struct {
+	// BufPtr is the start address of the area.
+	BufPtr unsafe.Pointer
+	// BufIOVA is the start IO address of the area.
+	BufIOVA uint64
+	// BufLen is the length of the area.
+	BufLen uintptr
+	// EltSize is the size of each data buffer carved from the area.
+	EltSize uint16
+}
+
+// CreateMbufPoolExtBuf creates mempool of mbufs with data buffers
+// pinned to external memory areas specified in extMem. Data buffers
+// are never freed back into the system, mbufs are created with
+// RTE_MBUF_F_EXTERNAL flag set and must not be reattached to other
+// external buffers.
+//
+// See CreateMbufPool for the meaning of other arguments.
+func CreateMbufPoolExtBuf(name string, n uint32, dataRoomSize uint16, extMem []ExtMem, opts ...Option) (*Mempool, error) {
+	conf := &mpConf{socket: C.SOCKET_ID_ANY}
+	for i := range opts {
+		opts[i].f(conf)
+	}
+
+	ext := make([]C.struct_rte_pktmbuf_extmem, len(extMem))
+	for i := range extMem {
+		ext[i].buf_ptr = extMem[i].BufPtr
+		ext[i].buf_iova = C.rte_iova_t(extMem[i].BufIOVA)
+		ext[i].buf_len = C.size_t(extMem[i].BufLen)
+		ext[i].elt_size = C.uint16_t(extMem[i].EltSize)
+	}
+
+	var pExt *C.struct_rte_pktmbuf_extmem
+	if len(ext) > 0 {
+		pExt = &ext[0]
+	}
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	mp := (*Mempool)(C.rte_pktmbuf_pool_create_extbuf(cname, C.uint(n),
+		conf.cacheSize, C.ushort(conf.privDataSize), C.uint16_t(dataRoomSize),
+		conf.socket, pExt, C.uint(len(ext))))
+
+	if mp == nil {
+		return nil, err()
+	}
+
+	return mp, nil
+}
@@ -50,6 +50,9 @@ type mpConf struct {
 
 	// ops
 	opsName *string
+
+	// typed pool
+	poison *byte
 }
 
 func err(n ...interface{}) error {
@@ -68,7 +71,7 @@ type Option struct {
 // OptOpsName specifies mempool operations implementation. Each
 // implementation is provided as a mempool driver so please be sure it
 // is loaded upon start of an application. This option is used in
-// CreateMbufPool only.
+// CreateMbufPool and NewPool only.
 //
 // OptOpsName sets the ops of a mempool. Currently implemented in DPDK
 // are: 'ring_mp_mc', 'ring_sp_mc', 'ring_mp_sc', 'ring_sp_sc',
@@ -115,6 +118,15 @@ func OptSocket(socket int) Option {
 	}}
 }
 
+// OptPoison enables poisoning of objects with pattern on Put and
+// checking the poison on Get to catch use-after-free. It is meant for
+// debugging and is used in NewPool only.
+func OptPoison(pattern byte) Option {
+	return Option{func(conf *mpConf) {
+		conf.poison = &pattern
+	}}
+}
+
 // OptFlag specifies various flags to use when creating mempool.
 func OptFlag(flag uint) Option {
 	return Option{func(conf *mpConf) {
@@ -361,3 +373,14 @@ func (mp *Mempool) GenericGet(objs []unsafe.Pointer, cache *Cache) error {
 		C.uint(len(objs)),
 		(*C.struct_rte_mempool_cache)(cache)))
 }
+
+//export goMemChunkCb
+func goMemChunkCb(mp *C.struct_rte_mempool, opaque unsafe.Pointer, memhdr *C.struct_rte_mempool_memhdr, idx C.uint) {
+	cb := *(*common.ObjectID)(opaque)
+	fn := callbacks.Read(cb).(func(*MemChunk))
+	fn(&MemChunk{
+		Addr: memhdr.addr,
+		IOVA: uint64(memhdr.iova),
+		Len:  uintptr(memhdr.len),
+	})
+}
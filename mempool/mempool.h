@@ -0,0 +1,90 @@
+#ifndef _MEMPOOL_H_
+#define _MEMPOOL_H_
+
+#include <errno.h>
+#include <string.h>
+
+#include <rte_config.h>
+#include <rte_version.h>
+#include <rte_lcore.h>
+#include <rte_mempool.h>
+
+#if defined(RTE_LIBRTE_MEMPOOL_STATS)
+#define GO_MEMPOOL_STATS 1
+#elif defined(RTE_LIBRTE_MEMPOOL_DEBUG) && RTE_VERSION < RTE_VERSION_NUM(21, 11, 0, 0)
+#define GO_MEMPOOL_STATS 1
+#endif
+
+struct go_mempool_stats {
+	uint64_t put_bulk;
+	uint64_t put_objs;
+	uint64_t get_success_bulk;
+	uint64_t get_success_objs;
+	uint64_t get_fail_bulk;
+	uint64_t get_fail_objs;
+	uint64_t get_success_blks;
+	uint64_t get_fail_blks;
+};
+
+static int
+go_mempool_stats(const struct rte_mempool *mp, struct go_mempool_stats *s)
+{
+#ifdef GO_MEMPOOL_STATS
+	unsigned int lcore_id;
+
+	memset(s, 0, sizeof(*s));
+	/* since 22.11 there is an extra slot for unregistered non-EAL threads */
+	for (lcore_id = 0; lcore_id < RTE_DIM(mp->stats); lcore_id++) {
+		s->put_bulk += mp->stats[lcore_id].put_bulk;
+		s->put_objs += mp->stats[lcore_id].put_objs;
+		s->get_success_bulk += mp->stats[lcore_id].get_success_bulk;
+		s->get_success_objs += mp->stats[lcore_id].get_success_objs;
+		s->get_fail_bulk += mp->stats[lcore_id].get_fail_bulk;
+		s->get_fail_objs += mp->stats[lcore_id].get_fail_objs;
+		s->get_success_blks += mp->stats[lcore_id].get_success_blks;
+		s->get_fail_blks += mp->stats[lcore_id].get_fail_blks;
+	}
+#if defined(RTE_LIBRTE_MEMPOOL_STATS) && RTE_VERSION >= RTE_VERSION_NUM(23, 3, 0, 0)
+	/* since 23.03 gets and puts served by the cache are counted there */
+	if (mp->cache_size != 0) {
+		for (lcore_id = 0; lcore_id < RTE_MAX_LCORE; lcore_id++) {
+			s->put_bulk += mp->local_cache[lcore_id].stats.put_bulk;
+			s->put_objs += mp->local_cache[lcore_id].stats.put_objs;
+			s->get_success_bulk += mp->local_cache[lcore_id].stats.get_success_bulk;
+			s->get_success_objs += mp->local_cache[lcore_id].stats.get_success_objs;
+		}
+	}
+#endif
+	return 0;
+#else
+	RTE_SET_USED(mp);
+	RTE_SET_USED(s);
+	return -ENOTSUP;
+#endif
+}
+
+static unsigned int
+go_mempool_cache_count(const struct rte_mempool *mp, unsigned int lcore_id)
+{
+	if (mp->cache_size == 0 || lcore_id >= RTE_MAX_LCORE)
+		return 0;
+	return mp->local_cache[lcore_id].len;
+}
+
+static unsigned int
+go_mempool_cache_count_total(const struct rte_mempool *mp)
+{
+	unsigned int lcore_id, n = 0;
+
+	for (lcore_id = 0; lcore_id < RTE_MAX_LCORE; lcore_id++)
+		n += go_mempool_cache_count(mp, lcore_id);
+	return n;
+}
+
+static const char *
+go_mempool_ops_name(const struct rte_mempool *mp)
+{
+	return rte_mempool_get_ops(mp->ops_index)->name;
+}
+
+#endif /* _MEMPOOL_H_ */
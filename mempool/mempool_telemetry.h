@@ -0,0 +1,78 @@
+#ifndef _MEMPOOL_TELEMETRY_H_
+#define _MEMPOOL_TELEMETRY_H_
+
+#include <rte_common.h>
+#include <rte_telemetry.h>
+
+#include "mempool.h"
+
+#if RTE_VERSION < RTE_VERSION_NUM(23, 3, 0, 0)
+#define tel_data_add_dict_uint rte_tel_data_add_dict_u64
+#else
+#define tel_data_add_dict_uint rte_tel_data_add_dict_uint
+#endif
+
+static void
+mempool_reap_names(struct rte_mempool *mp, void *arg)
+{
+	struct rte_tel_data *d = (struct rte_tel_data *)(arg);
+	rte_tel_data_add_array_string(d, mp->name);
+}
+
+static int
+mempool_list(
+		__rte_unused const char *cmd,
+		__rte_unused const char *params,
+		struct rte_tel_data *d)
+{
+	rte_tel_data_start_array(d, RTE_TEL_STRING_VAL);
+	rte_mempool_walk(mempool_reap_names, d);
+	return 0;
+}
+
+static int
+mempool_info(
+		__rte_unused const char *cmd,
+		const char *name,
+		struct rte_tel_data *d)
+{
+	struct go_mempool_stats s;
+
+	if (!name || strlen(name) == 0)
+		return -EINVAL;
+
+	struct rte_mempool *mp = rte_mempool_lookup(name);
+	if (mp == NULL)
+		return -ENOENT;
+
+	rte_tel_data_start_dict(d);
+	rte_tel_data_add_dict_string(d, "name", mp->name);
+	rte_tel_data_add_dict_string(d, "ops_name", go_mempool_ops_name(mp));
+	rte_tel_data_add_dict_int(d, "socket_id", mp->socket_id);
+	tel_data_add_dict_uint(d, "flags", mp->flags);
+	tel_data_add_dict_uint(d, "size", mp->size);
+	tel_data_add_dict_uint(d, "elt_size", mp->elt_size);
+	tel_data_add_dict_uint(d, "cache_size", mp->cache_size);
+	tel_data_add_dict_uint(d, "cache_count", go_mempool_cache_count_total(mp));
+	tel_data_add_dict_uint(d, "avail_count", rte_mempool_avail_count(mp));
+	tel_data_add_dict_uint(d, "in_use_count", rte_mempool_in_use_count(mp));
+	tel_data_add_dict_uint(d, "nb_mem_chunks", mp->nb_mem_chunks);
+
+	if (go_mempool_stats(mp, &s) == 0) {
+		tel_data_add_dict_uint(d, "put_bulk", s.put_bulk);
+		tel_data_add_dict_uint(d, "put_objs", s.put_objs);
+		tel_data_add_dict_uint(d, "get_success_bulk", s.get_success_bulk);
+		tel_data_add_dict_uint(d, "get_success_objs", s.get_success_objs);
+		tel_data_add_dict_uint(d, "get_fail_bulk", s.get_fail_bulk);
+		tel_data_add_dict_uint(d, "get_fail_objs", s.get_fail_objs);
+		tel_data_add_dict_uint(d, "get_success_blks", s.get_success_blks);
+		tel_data_add_dict_uint(d, "get_fail_blks", s.get_fail_blks);
+	}
+
+	return 0;
+}
+
+telemetry_cb mempool_list_cb = mempool_list;
+telemetry_cb mempool_info_cb = mempool_info;
+
+#endif /* _MEMPOOL_TELEMETRY_H_ */
@@ -12,6 +12,7 @@ import (
 	"github.com/tianyuansun/go-dpdk/common"
 	"github.com/tianyuansun/go-dpdk/eal"
 	"github.com/tianyuansun/go-dpdk/mempool"
+	"github.com/tianyuansun/go-dpdk/memzone"
 )
 
 func assert(t testing.TB, expected bool, args ...interface{}) {
@@ -280,6 +281,41 @@ func TestMbufpoolPriv(t *testing.T) {
 	})
 }
 
+func TestCreateMbufPoolExtBuf(t *testing.T) {
+	eal.InitOnceSafe("test", 4)
+
+	err := eal.ExecOnMain(func(ctx *eal.LcoreCtx) {
+		n := uint32(16)
+		eltSize := uint16(2048)
+		mz, err := memzone.Reserve("test_mz_extbuf", uintptr(n)*uintptr(eltSize),
+			memzone.OptAligned(4096))
+		assert(t, err == nil, err)
+		defer mz.Free()
+
+		mp, err := mempool.CreateMbufPoolExtBuf("test_mbuf_pool_extbuf",
+			n, eltSize, []mempool.ExtMem{{
+				BufPtr:  mz.Addr(),
+				BufIOVA: mz.IOVA(),
+				BufLen:  mz.Len(),
+				EltSize: eltSize,
+			}})
+		assert(t, err == nil, err)
+		defer mp.Free()
+		assert(t, mp.AvailCount() == int(n))
+
+		m := mbuf.PktMbufAlloc(mp)
+		assert(t, m != nil)
+		defer m.PktMbufFree()
+
+		// data buffer is carved from the memzone
+		data := mbuf.GetPacketDataStartPointer(m)
+		start := uintptr(mz.Addr())
+		assert(t, data >= start && data < start+mz.Len())
+		assert(t, m.BufLen() == eltSize)
+	})
+	assert(t, err == nil, err)
+}
+
 type someStruct struct {
 	intField    int
 	stringField string
@@ -392,3 +428,144 @@ func BenchmarkAllocFromMempool(b *testing.B) {
 		}
 	})
 }
+
+type poolItem struct {
+	ID    uint64
+	Magic uint32
+	Data  [20]byte
+}
+
+func TestPool(t *testing.T) {
+	eal.InitOnceSafe("test", 4)
+
+	err := eal.ExecOnMain(func(ctx *eal.LcoreCtx) {
+		n := uint32(64)
+		inits := 0
+		p, err := mempool.NewPool("test_typed_pool", n, func(it *poolItem) {
+			it.Magic = 0xcafe
+			inits++
+		}, mempool.OptCacheSize(8))
+		assert(t, err == nil, err)
+		defer p.Free()
+		assert(t, inits == int(n), inits)
+		assert(t, p.Mempool().AvailCount() == int(n))
+
+		objs := make([]*poolItem, 10)
+		assert(t, p.Get(objs, nil) == nil)
+		assert(t, p.Mempool().InUseCount() == 10)
+		for i, obj := range objs {
+			assert(t, obj != nil)
+			assert(t, obj.Magic == 0xcafe, obj.Magic)
+			obj.ID = uint64(i)
+		}
+		p.Put(objs, nil)
+		assert(t, p.Mempool().InUseCount() == 0)
+
+		// user-owned cache
+		cache, err := mempool.CreateCache(mempool.OptCacheSize(16))
+		assert(t, err == nil, err)
+		defer cache.Free()
+
+		assert(t, p.Get(objs, cache) == nil)
+		p.Put(objs, cache)
+		cache.Flush(p.Mempool())
+		assert(t, p.Mempool().AvailCount() == int(n))
+
+		// not enough objects
+		assert(t, p.Get(make([]*poolItem, n+1), nil) != nil)
+		assert(t, p.Mempool().AvailCount() == int(n))
+	})
+	assert(t, err == nil, err)
+}
+
+func TestPoolPoison(t *testing.T) {
+	eal.InitOnceSafe("test", 4)
+
+	err := eal.ExecOnMain(func(ctx *eal.LcoreCtx) {
+		inits := 0
+		p, err := mempool.NewPool("test_typed_pool_poison", 1, func(it *poolItem) {
+			it.Magic = 0xcafe
+			inits++
+		}, mempool.OptPoison(0xa5))
+		assert(t, err == nil, err)
+		defer p.Free()
+		assert(t, inits == 1, inits)
+
+		objs := make([]*poolItem, 1)
+		assert(t, p.Get(objs, nil) == nil)
+		obj := objs[0]
+		assert(t, obj.Magic == 0xcafe, obj.Magic)
+		obj.ID = 1
+
+		p.Put(objs, nil)
+		assert(t, obj.ID == 0xa5a5a5a5a5a5a5a5, obj.ID)
+
+		// object is restored on Get without calling init again
+		assert(t, p.Get(objs, nil) == nil)
+		assert(t, objs[0] == obj)
+		assert(t, obj.Magic == 0xcafe, obj.Magic)
+		assert(t, obj.ID == 0, obj.ID)
+		assert(t, inits == 1, inits)
+		p.Put(objs, nil)
+
+		// use after free
+		obj.Data[3] = 0
+		func() {
+			defer func() {
+				assert(t, recover() != nil, "use after free not detected")
+			}()
+			p.Get(objs, nil)
+		}()
+
+		// objects are put back before panic
+		assert(t, p.Mempool().AvailCount() == 1, p.Mempool().AvailCount())
+	})
+	assert(t, err == nil, err)
+}
+
+func TestMempoolStats(t *testing.T) {
+	doOnMain(t, func(p *mempool.Mempool, data []byte) {
+		assert(t, p.Name() == "test_mbuf_pool", p.Name())
+		assert(t, p.Size() == 10240, p.Size())
+		assert(t, p.CacheSize() == 32, p.CacheSize())
+		assert(t, p.OpsName() == "stack", p.OpsName())
+		assert(t, p.SocketID() == int(eal.SocketID()), p.SocketID())
+		assert(t, p.EltSize() > 2048, p.EltSize())
+
+		chunks := p.MemChunks()
+		assert(t, len(chunks) > 0)
+		assert(t, len(chunks) == p.NbMemChunks(), len(chunks), p.NbMemChunks())
+		total := uintptr(0)
+		for _, c := range chunks {
+			assert(t, c.Addr != nil)
+			total += c.Len
+		}
+		assert(t, total >= uintptr(p.Size())*uintptr(p.EltSize()), total)
+
+		// default lcore cache is filled by allocation
+		assert(t, p.CacheCountTotal() == 0)
+		m := mbuf.PktMbufAlloc(p)
+		m.PktMbufFree()
+		n := p.CacheCount(eal.LcoreID())
+		assert(t, n > 0, n)
+		assert(t, p.CacheCountTotal() == n)
+		assert(t, p.AvailCount() == int(p.Size()))
+
+		var s mempool.Stats
+		if err := p.Stats(&s); err == nil {
+			assert(t, s.GetSuccessObjs > 0 && s.PutObjs > 0, s)
+		} else {
+			assert(t, err == syscall.ENOTSUP, err)
+		}
+
+		p.Audit()
+
+		var b bytes.Buffer
+		assert(t, p.Dump(&b) == nil)
+		assert(t, bytes.Contains(b.Bytes(), []byte("mempool <test_mbuf_pool>@")), b.String())
+
+		b.Reset()
+		assert(t, mempool.ListDump(&b) == nil)
+		assert(t, bytes.Contains(b.Bytes(), []byte("test_mbuf_pool")), b.String())
+	})
+}
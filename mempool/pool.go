@@ -0,0 +1,152 @@
+package mempool
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Pool is a mempool of objects of type T. Objects reside in DPDK
+// memory so T must not contain Go pointers.
+type Pool[T any] struct {
+	mp     *Mempool
+	init   func(*T)
+	poison *byte
+
+	// initialized objects restored after poison check
+	templates map[uintptr]T
+}
+
+// NewPool creates mempool of n objects of type T. The mempool is
+// created with CreateEmpty and populated with PopulateDefault. If
+// init is not nil it is called for each object once the mempool is
+// populated.
+//
+// If OptOpsName is not specified, ring-based operations are chosen
+// according to SPPut and SCGet flags. Specify OptPoison to enable
+// use-after-free detection.
+func NewPool[T any](name string, n uint32, init func(*T), opts ...Option) (*Pool[T], error) {
+	var zero T
+
+	conf := &mpConf{}
+	for i := range opts {
+		opts[i].f(conf)
+	}
+
+	mp, e := CreateEmpty(name, n, uint32(unsafe.Sizeof(zero)), opts...)
+	if e != nil {
+		return nil, e
+	}
+
+	if conf.opsName != nil {
+		e = mp.SetOpsByName(*conf.opsName, nil)
+	} else {
+		e = mp.SetOpsRing()
+	}
+
+	if e == nil {
+		_, e = mp.PopulateDefault()
+	}
+
+	if e != nil {
+		mp.Free()
+		return nil, e
+	}
+
+	p := &Pool[T]{mp: mp, init: init, poison: conf.poison}
+	if p.poison != nil {
+		p.templates = make(map[uintptr]T, n)
+	}
+
+	mp.ObjIter(func(b []byte) {
+		obj := (*T)(unsafe.Pointer(&b[0]))
+		if p.init != nil {
+			p.init(obj)
+		}
+		if p.poison != nil {
+			p.templates[uintptr(unsafe.Pointer(obj))] = *obj
+			fill(b, *p.poison)
+		}
+	})
+
+	return p, nil
+}
+
+// Mempool returns underlying mempool.
+func (p *Pool[T]) Mempool() *Mempool {
+	return p.mp
+}
+
+// Free the pool. All objects must be returned into the pool before
+// calling this function.
+func (p *Pool[T]) Free() {
+	p.mp.Free()
+}
+
+// Get gets len(objs) objects from the pool using optional cache. On
+// error none of the objects are retrieved.
+//
+// If poisoning is enabled, each object is checked to be left intact
+// since it was Put and the function panics otherwise, after putting
+// the objects back. The object is then restored to the state init
+// left it in at populate time.
+func (p *Pool[T]) Get(objs []*T, cache *Cache) error {
+	if len(objs) == 0 {
+		return nil
+	}
+
+	if e := p.mp.GenericGet(ptrs(objs), cache); e != nil {
+		return e
+	}
+
+	if p.poison != nil {
+		p.unpoison(objs, cache)
+	}
+	return nil
+}
+
+// Put puts objects back into the pool using optional cache. If
+// poisoning is enabled, objects are filled with the poison pattern.
+func (p *Pool[T]) Put(objs []*T, cache *Cache) {
+	if len(objs) == 0 {
+		return
+	}
+
+	if p.poison != nil {
+		for _, obj := range objs {
+			fill(objBytes(obj), *p.poison)
+		}
+	}
+
+	p.mp.GenericPut(ptrs(objs), cache)
+}
+
+func (p *Pool[T]) unpoison(objs []*T, cache *Cache) {
+	for _, obj := range objs {
+		b := objBytes(obj)
+		for i := range b {
+			if b[i] != *p.poison {
+				p.Put(objs, cache)
+				panic(fmt.Sprintf("mempool %p: object %p modified at offset %d after Put",
+					p.mp, obj, i))
+			}
+		}
+	}
+
+	for _, obj := range objs {
+		*obj = p.templates[uintptr(unsafe.Pointer(obj))]
+	}
+}
+
+func ptrs[T any](objs []*T) []unsafe.Pointer {
+	return unsafe.Slice((*unsafe.Pointer)(unsafe.Pointer(&objs[0])), len(objs))
+}
+
+func objBytes[T any](obj *T) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(obj)), unsafe.Sizeof(*obj))
+}
+
+func fill(b []byte, pattern byte) {
+	for i := range b {
+		b[i] = pattern
+	}
+}
@@ -0,0 +1,148 @@
+package mempool
+
+/*
+#include <stdio.h>
+#include "mempool.h"
+
+extern void goMemChunkCb(struct rte_mempool *, void *, struct rte_mempool_memhdr *, unsigned);
+*/
+import "C"
+
+import (
+	"io"
+	"syscall"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// Stats is the statistics of a mempool summed across all lcores.
+// It's available only if DPDK is built with RTE_LIBRTE_MEMPOOL_STATS.
+type Stats struct {
+	PutBulk        uint64 // Number of puts.
+	PutObjs        uint64 // Number of objects successfully put.
+	GetSuccessBulk uint64 // Successful allocation number.
+	GetSuccessObjs uint64 // Objects successfully allocated.
+	GetFailBulk    uint64 // Failed allocation number.
+	GetFailObjs    uint64 // Objects that failed to be allocated.
+	GetSuccessBlks uint64 // Successful allocation number of contiguous blocks.
+	GetFailBlks    uint64 // Failed allocation number of contiguous blocks.
+}
+
+// MemChunk describes a memory chunk populated in a mempool.
+type MemChunk struct {
+	Addr unsafe.Pointer // Virtual address of the chunk.
+	IOVA uint64         // IO address of the chunk.
+	Len  uintptr        // Length of the chunk.
+}
+
+// Name returns name of the mempool.
+func (mp *Mempool) Name() string {
+	return C.GoString(&mp.name[0])
+}
+
+// Size returns maximum number of elements in the mempool.
+func (mp *Mempool) Size() uint32 {
+	return uint32(mp.size)
+}
+
+// EltSize returns size of an element.
+func (mp *Mempool) EltSize() uint32 {
+	return uint32(mp.elt_size)
+}
+
+// CacheSize returns size of per-lcore default local cache.
+func (mp *Mempool) CacheSize() uint32 {
+	return uint32(mp.cache_size)
+}
+
+// Flags returns flags of the mempool.
+func (mp *Mempool) Flags() uint {
+	return uint(mp.flags)
+}
+
+// SocketID returns NUMA socket ID of the mempool.
+func (mp *Mempool) SocketID() int {
+	return int(mp.socket_id)
+}
+
+// OpsName returns name of mempool operations implementation.
+func (mp *Mempool) OpsName() string {
+	return C.GoString(C.go_mempool_ops_name((*C.struct_rte_mempool)(mp)))
+}
+
+// CacheCount returns the number of objects in default local cache of
+// the specified lcore.
+func (mp *Mempool) CacheCount(lcoreID uint) int {
+	return int(C.go_mempool_cache_count((*C.struct_rte_mempool)(mp), C.uint(lcoreID)))
+}
+
+// CacheCountTotal returns the number of objects in default local
+// caches of all lcores. User-owned mempool caches are not accounted
+// for.
+func (mp *Mempool) CacheCountTotal() int {
+	return int(C.go_mempool_cache_count_total((*C.struct_rte_mempool)(mp)))
+}
+
+// NbMemChunks returns number of memory chunks populated in the
+// mempool.
+func (mp *Mempool) NbMemChunks() int {
+	return int(mp.nb_mem_chunks)
+}
+
+// MemChunks returns memory chunks populated in the mempool.
+func (mp *Mempool) MemChunks() []MemChunk {
+	var chunks []MemChunk
+	fn := func(c *MemChunk) {
+		chunks = append(chunks, *c)
+	}
+
+	cb := callbacks.Create(fn)
+	defer callbacks.Delete(cb)
+
+	C.rte_mempool_mem_iter((*C.struct_rte_mempool)(mp), (*[0]byte)(C.goMemChunkCb), unsafe.Pointer(&cb))
+	return chunks
+}
+
+// Stats retrieves statistics of the mempool into s. ENOTSUP is
+// returned if DPDK is built without mempool statistics.
+func (mp *Mempool) Stats(s *Stats) error {
+	var cs C.struct_go_mempool_stats
+	if C.go_mempool_stats((*C.struct_rte_mempool)(mp), &cs) != 0 {
+		return syscall.ENOTSUP
+	}
+
+	*s = Stats{
+		PutBulk:        uint64(cs.put_bulk),
+		PutObjs:        uint64(cs.put_objs),
+		GetSuccessBulk: uint64(cs.get_success_bulk),
+		GetSuccessObjs: uint64(cs.get_success_objs),
+		GetFailBulk:    uint64(cs.get_fail_bulk),
+		GetFailObjs:    uint64(cs.get_fail_objs),
+		GetSuccessBlks: uint64(cs.get_success_blks),
+		GetFailBlks:    uint64(cs.get_fail_blks),
+	}
+	return nil
+}
+
+// Audit checks the consistency of mempool objects. The process is
+// aborted if inconsistency is found. Checks are performed only if
+// DPDK is built with RTE_LIBRTE_MEMPOOL_DEBUG, otherwise it's a
+// no-op.
+func (mp *Mempool) Audit() {
+	C.rte_mempool_audit((*C.struct_rte_mempool)(mp))
+}
+
+// Dump writes the status of the mempool into w.
+func (mp *Mempool) Dump(w io.Writer) error {
+	return common.DumpTo(w, func(fp unsafe.Pointer) {
+		C.rte_mempool_dump((*C.FILE)(fp), (*C.struct_rte_mempool)(mp))
+	})
+}
+
+// ListDump writes the status of all mempools into w.
+func ListDump(w io.Writer) error {
+	return common.DumpTo(w, func(fp unsafe.Pointer) {
+		C.rte_mempool_list_dump((*C.FILE)(fp))
+	})
+}
@@ -19,7 +19,8 @@ package memzone
 #include <rte_memzone.h>
 
 enum {
-	OFF_MZ_ADDR = offsetof(struct rte_memzone, addr)
+	OFF_MZ_ADDR = offsetof(struct rte_memzone, addr),
+	OFF_MZ_IOVA = offsetof(struct rte_memzone, iova)
 };
 
 extern void mzCb(struct rte_memzone *, void *);
@@ -190,6 +191,12 @@ func (mz *Memzone) Addr() unsafe.Pointer {
 	return *(*unsafe.Pointer)(addr)
 }
 
+// IOVA returns start IO address of the memzone.
+func (mz *Memzone) IOVA() uint64 {
+	iova := unsafe.Pointer(uintptr(unsafe.Pointer(mz)) + uintptr(C.OFF_MZ_IOVA))
+	return *(*uint64)(iova)
+}
+
 // Len returns length of the memzone.
 func (mz *Memzone) Len() uintptr {
 	return uintptr((*C.struct_rte_memzone)(mz).len)
@@ -0,0 +1,6 @@
+package mp
+
+/*
+#cgo pkg-config: libdpdk
+*/
+import "C"
@@ -0,0 +1,44 @@
+package mp
+
+/*
+#include <rte_config.h>
+#include <rte_eal.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+//export goMpAction
+func goMpAction(cm *C.struct_rte_mp_msg, peer unsafe.Pointer) C.int {
+	msg := msgFromC(cm)
+
+	actions.Lock()
+	fn, ok := actions.m[msg.Name]
+	actions.Unlock()
+
+	if !ok {
+		return -1
+	}
+
+	if fn(msg, &Peer{peer}) != nil {
+		return -1
+	}
+	return 0
+}
+
+//export goMpAsyncReply
+func goMpAsyncReply(req *C.struct_rte_mp_msg, r *C.struct_rte_mp_reply) C.int {
+	msg := msgFromC(req)
+
+	pending.Lock()
+	fn, ok := pending.m[msg.Name]
+	delete(pending.m, msg.Name)
+	pending.Unlock()
+
+	if ok {
+		fn(msg, replyFromC(r))
+	}
+	return 0
+}
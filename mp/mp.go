@@ -0,0 +1,286 @@
+/*
+Package mp wraps EAL multi-process communication channel (rte_mp).
+
+Primary and secondary processes sharing the same file prefix may
+exchange short messages optionally carrying file descriptors. A
+process registers an action for a message name and peers send
+one-way messages or requests expecting replies.
+
+Please note that IPC is disabled if EAL is initialized with
+--in-memory or --no-shconf options. Secondary processes can't attach
+once a non-EAL lcore is registered with eal.ThreadRegister.
+*/
+package mp
+
+/*
+#include <stdlib.h>
+#include <string.h>
+#include <time.h>
+
+#include <rte_config.h>
+#include <rte_eal.h>
+#include <rte_errno.h>
+
+extern int goMpAction(struct rte_mp_msg *msg, void *peer);
+extern int goMpAsyncReply(struct rte_mp_msg *req, struct rte_mp_reply *reply);
+
+static int mp_action(const struct rte_mp_msg *msg, const void *peer) {
+	return goMpAction((struct rte_mp_msg *)msg, (void *)peer);
+}
+
+static int mp_async_reply(const struct rte_mp_msg *req, const struct rte_mp_reply *reply) {
+	return goMpAsyncReply((struct rte_mp_msg *)req, (struct rte_mp_reply *)reply);
+}
+
+static int mp_action_register(const char *name) {
+	return rte_mp_action_register(name, mp_action);
+}
+
+static int mp_request_async(struct rte_mp_msg *req, const struct timespec *ts) {
+	return rte_mp_request_async(req, ts, mp_async_reply);
+}
+
+static struct rte_mp_msg *mp_reply_msg(struct rte_mp_reply *reply, int i) {
+	return &reply->msgs[i];
+}
+*/
+import "C"
+
+import (
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// Limits of message contents.
+const (
+	MaxNameLen  = C.RTE_MP_MAX_NAME_LEN
+	MaxParamLen = C.RTE_MP_MAX_PARAM_LEN
+	MaxFdNum    = C.RTE_MP_MAX_FD_NUM
+)
+
+// Msg is a message exchanged between processes.
+type Msg struct {
+	// Name of the message. It selects the action on the receiving
+	// side. Must be shorter than MaxNameLen.
+	Name string
+
+	// Param is the message payload of up to MaxParamLen bytes.
+	Param []byte
+
+	// Fds is the list of up to MaxFdNum file descriptors. On the
+	// receiving side they are duplicates owned by the receiver.
+	Fds []int
+}
+
+// Reply is a result of a request.
+type Reply struct {
+	// NbSent is the number of peers the request was sent to.
+	NbSent int
+
+	// NbReceived is the number of received replies.
+	NbReceived int
+
+	// Msgs are the received replies.
+	Msgs []Msg
+}
+
+// Peer is an opaque sender of a message. It is valid only during
+// execution of the Action it is passed to.
+type Peer struct {
+	p unsafe.Pointer
+}
+
+// Action is a handler of messages with a particular name. It is
+// executed in EAL IPC thread. Returned error is logged by EAL.
+type Action func(msg *Msg, peer *Peer) error
+
+// AsyncReply is a handler of replies for a request sent by
+// RequestAsync. It is executed in EAL interrupt thread.
+type AsyncReply func(req *Msg, reply *Reply)
+
+var (
+	actions = struct {
+		sync.Mutex
+		m map[string]Action
+	}{m: map[string]Action{}}
+
+	pending = struct {
+		sync.Mutex
+		m map[string]AsyncReply
+	}{m: map[string]AsyncReply{}}
+)
+
+func err(n ...interface{}) error {
+	if len(n) == 0 {
+		return common.RteErrno()
+	}
+
+	return common.IntToErr(n[0])
+}
+
+func (m *Msg) toC(cm *C.struct_rte_mp_msg) error {
+	if len(m.Name) == 0 || len(m.Name) >= MaxNameLen ||
+		len(m.Param) > MaxParamLen || len(m.Fds) > MaxFdNum {
+		return common.IntErr(-int64(syscall.EINVAL))
+	}
+
+	name := unsafe.Slice((*byte)(unsafe.Pointer(&cm.name[0])), MaxNameLen)
+	name[copy(name, m.Name)] = 0
+	if len(m.Param) > 0 {
+		C.memcpy(unsafe.Pointer(&cm.param[0]), unsafe.Pointer(&m.Param[0]), C.size_t(len(m.Param)))
+	}
+	cm.len_param = C.int(len(m.Param))
+	for i, fd := range m.Fds {
+		cm.fds[i] = C.int(fd)
+	}
+	cm.num_fds = C.int(len(m.Fds))
+	return nil
+}
+
+func msgFromC(cm *C.struct_rte_mp_msg) *Msg {
+	m := &Msg{
+		Name:  C.GoString(&cm.name[0]),
+		Param: C.GoBytes(unsafe.Pointer(&cm.param[0]), cm.len_param),
+	}
+	for i := 0; i < int(cm.num_fds); i++ {
+		m.Fds = append(m.Fds, int(cm.fds[i]))
+	}
+	return m
+}
+
+func replyFromC(r *C.struct_rte_mp_reply) *Reply {
+	reply := &Reply{
+		NbSent:     int(r.nb_sent),
+		NbReceived: int(r.nb_received),
+		Msgs:       make([]Msg, r.nb_received),
+	}
+	for i := range reply.Msgs {
+		reply.Msgs[i] = *msgFromC(C.mp_reply_msg(r, C.int(i)))
+	}
+	return reply
+}
+
+func timespec(d time.Duration) C.struct_timespec {
+	return C.struct_timespec{
+		tv_sec:  C.time_t(d / time.Second),
+		tv_nsec: C.long(d % time.Second),
+	}
+}
+
+// ActionRegister registers fn to handle messages with specified name.
+// Only one action may be registered for a name.
+func ActionRegister(name string, fn Action) error {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	actions.Lock()
+	defer actions.Unlock()
+
+	if _, ok := actions.m[name]; ok {
+		return common.IntErr(-int64(syscall.EEXIST))
+	}
+
+	if C.mp_action_register(cname) < 0 {
+		return err()
+	}
+
+	actions.m[name] = fn
+	return nil
+}
+
+// ActionUnregister removes the action registered for specified name.
+func ActionUnregister(name string) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	actions.Lock()
+	defer actions.Unlock()
+
+	C.rte_mp_action_unregister(cname)
+	delete(actions.m, name)
+}
+
+// SendMsg sends a one-way message. Primary process broadcasts the
+// message to all secondary processes, secondary process sends it to
+// the primary.
+func SendMsg(msg *Msg) error {
+	var cm C.struct_rte_mp_msg
+	if e := msg.toC(&cm); e != nil {
+		return e
+	}
+
+	if C.rte_mp_sendmsg(&cm) < 0 {
+		return err()
+	}
+	return nil
+}
+
+// RequestSync sends a request and waits for replies for at most
+// timeout. Primary process sends the request to all secondary
+// processes, secondary process sends it to the primary.
+//
+// Please note that it must not be called from an Action since it
+// would block EAL IPC thread.
+func RequestSync(req *Msg, timeout time.Duration) (*Reply, error) {
+	var cm C.struct_rte_mp_msg
+	if e := req.toC(&cm); e != nil {
+		return nil, e
+	}
+
+	var r C.struct_rte_mp_reply
+	ts := timespec(timeout)
+	rc := C.rte_mp_request_sync(&cm, &r, &ts)
+	defer C.free(unsafe.Pointer(r.msgs))
+
+	if rc < 0 {
+		return nil, err()
+	}
+	return replyFromC(&r), nil
+}
+
+// RequestAsync sends a request and returns immediately. fn is called
+// with all received replies once every peer replied or timeout
+// expired.
+//
+// Only one asynchronous request with the same name may be pending at
+// a time.
+func RequestAsync(req *Msg, timeout time.Duration, fn AsyncReply) error {
+	var cm C.struct_rte_mp_msg
+	if e := req.toC(&cm); e != nil {
+		return e
+	}
+
+	pending.Lock()
+	defer pending.Unlock()
+
+	if _, ok := pending.m[req.Name]; ok {
+		return common.IntErr(-int64(syscall.EEXIST))
+	}
+
+	ts := timespec(timeout)
+	if C.mp_request_async(&cm, &ts) < 0 {
+		return err()
+	}
+
+	pending.m[req.Name] = fn
+	return nil
+}
+
+// Reply sends msg as a reply to the request received from peer. It
+// must be called within the Action handling the request and msg.Name
+// should be the name of the request.
+func (p *Peer) Reply(msg *Msg) error {
+	var cm C.struct_rte_mp_msg
+	if e := msg.toC(&cm); e != nil {
+		return e
+	}
+
+	if C.rte_mp_reply(&cm, (*C.char)(p.p)) < 0 {
+		return err()
+	}
+	return nil
+}
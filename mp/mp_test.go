@@ -0,0 +1,166 @@
+package mp_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/eal"
+	"github.com/tianyuansun/go-dpdk/mp"
+)
+
+const (
+	envPrefix = "GO_DPDK_MP_TEST_PREFIX"
+
+	// secondary process exit code if it fails to initialize EAL;
+	// must differ from 2 used by Go runtime on panic
+	exitNoEAL = 77
+
+	msgPing = "go_dpdk_test_ping"
+	msgFd   = "go_dpdk_test_fd"
+)
+
+func ealConfig(prefix string, procType string) *eal.Config {
+	// IPC doesn't work with --in-memory since it disables shared
+	// runtime files.
+	return &eal.Config{
+		Program:    "test-mp",
+		LcoreMap:   map[uint][]uint{0: {0}},
+		Memory:     128,
+		NoHuge:     true,
+		NoPCI:      true,
+		FilePrefix: prefix,
+		ProcType:   procType,
+	}
+}
+
+func secondary(prefix string) int {
+	if err := eal.InitConfig(ealConfig(prefix, eal.ProcTypeSecondary)); err != nil {
+		fmt.Fprintln(os.Stderr, "eal init:", err)
+		return exitNoEAL
+	}
+
+	check := func(reply *mp.Reply, expected string) error {
+		if reply.NbSent != 1 || reply.NbReceived != 1 {
+			return fmt.Errorf("sent %d, received %d", reply.NbSent, reply.NbReceived)
+		}
+		if m := reply.Msgs[0]; m.Name != msgPing || string(m.Param) != expected {
+			return fmt.Errorf("unexpected reply %s: %q", m.Name, m.Param)
+		}
+		return nil
+	}
+
+	reply, err := mp.RequestSync(&mp.Msg{Name: msgPing, Param: []byte("sync")}, 5*time.Second)
+	if err == nil {
+		err = check(reply, "pong:sync")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "request sync:", err)
+		return 1
+	}
+
+	ch := make(chan *mp.Reply, 1)
+	err = mp.RequestAsync(&mp.Msg{Name: msgPing, Param: []byte("async")}, 5*time.Second,
+		func(req *mp.Msg, reply *mp.Reply) {
+			ch <- reply
+		})
+	if err == nil {
+		err = check(<-ch, "pong:async")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "request async:", err)
+		return 1
+	}
+
+	// fd 3 is the write end of the pipe passed by primary
+	if err := mp.SendMsg(&mp.Msg{Name: msgFd, Fds: []int{3}}); err != nil {
+		fmt.Fprintln(os.Stderr, "send msg:", err)
+		return 1
+	}
+
+	return 0
+}
+
+func TestMain(m *testing.M) {
+	if prefix := os.Getenv(envPrefix); prefix != "" {
+		os.Exit(secondary(prefix))
+	}
+	os.Exit(m.Run())
+}
+
+func TestMp(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	prefix := "go_dpdk_mp_" + strconv.Itoa(os.Getpid())
+	argv, err := ealConfig(prefix, eal.ProcTypePrimary).Args()
+	assert(err == nil, err)
+	eal.InitOnce(argv)
+
+	assert(mp.ActionRegister("", nil) != nil)
+	assert(mp.SendMsg(&mp.Msg{Name: msgPing, Param: make([]byte, mp.MaxParamLen+1)}) != nil)
+
+	err = mp.ActionRegister(msgPing, func(msg *mp.Msg, peer *mp.Peer) error {
+		return peer.Reply(&mp.Msg{Name: msg.Name, Param: append([]byte("pong:"), msg.Param...)})
+	})
+	assert(err == nil, err)
+	defer mp.ActionUnregister(msgPing)
+	assert(mp.ActionRegister(msgPing, nil) != nil)
+
+	fds := make(chan int, 1)
+	err = mp.ActionRegister(msgFd, func(msg *mp.Msg, peer *mp.Peer) error {
+		if len(msg.Fds) != 1 {
+			return fmt.Errorf("expected 1 fd, got %d", len(msg.Fds))
+		}
+		fds <- msg.Fds[0]
+		return nil
+	})
+	assert(err == nil, err)
+	defer mp.ActionUnregister(msgFd)
+
+	// no secondary processes yet
+	reply, err := mp.RequestSync(&mp.Msg{Name: msgPing}, time.Second)
+	assert(err == nil, err)
+	assert(reply.NbSent == 0 && len(reply.Msgs) == 0, reply)
+
+	r, w, err := os.Pipe()
+	assert(err == nil, err)
+	defer r.Close()
+
+	out := &bytes.Buffer{}
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	cmd.Env = append(os.Environ(), envPrefix+"="+prefix)
+	cmd.ExtraFiles = []*os.File{w}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	assert(cmd.Start() == nil)
+	w.Close()
+
+	fd := -1
+	select {
+	case fd = <-fds:
+	case <-time.After(10 * time.Second):
+	}
+
+	err = cmd.Wait()
+	if e, ok := err.(*exec.ExitError); ok && e.ExitCode() == exitNoEAL {
+		t.Skipf("secondary process failed to attach:\n%s", out)
+	}
+	assert(err == nil, err, out)
+
+	// received fd is a duplicate of the pipe write end
+	assert(fd >= 0, "no fd received")
+	f := os.NewFile(uintptr(fd), "pipe")
+	_, err = f.Write([]byte("hello"))
+	assert(err == nil, err)
+	f.Close()
+
+	b, err := io.ReadAll(r)
+	assert(err == nil, err)
+	assert(string(b) == "hello", b)
+}
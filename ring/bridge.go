@@ -0,0 +1,206 @@
+package ring
+
+import (
+	"context"
+	"runtime"
+	"time"
+	"unsafe"
+)
+
+// Backoff implements adaptive waiting for a ring to become ready: it
+// busy-polls first, then yields the processor to other goroutines and
+// then sleeps with exponentially growing intervals.
+type Backoff struct {
+	// Spins is the number of immediate retries.
+	Spins int
+	// Yields is the number of retries after runtime.Gosched.
+	Yields int
+	// MinSleep is the first sleep interval after spins and yields
+	// are exhausted.
+	MinSleep time.Duration
+	// MaxSleep is the upper bound of sleep interval.
+	MaxSleep time.Duration
+
+	n     int
+	sleep time.Duration
+}
+
+// DefaultBackoff is the backoff used by Bridge unless OptBackoff is
+// specified.
+var DefaultBackoff = Backoff{
+	Spins:    128,
+	Yields:   16,
+	MinSleep: 10 * time.Microsecond,
+	MaxSleep: time.Millisecond,
+}
+
+// Reset restarts backoff from spinning.
+func (b *Backoff) Reset() {
+	b.n = 0
+	b.sleep = 0
+}
+
+// Wait waits before the next retry according to the current backoff
+// phase. Error is returned if ctx is done.
+func (b *Backoff) Wait(ctx context.Context) error {
+	switch {
+	case b.n < b.Spins:
+	case b.n < b.Spins+b.Yields:
+		runtime.Gosched()
+	default:
+		b.sleep *= 2
+		if b.sleep < b.MinSleep {
+			b.sleep = b.MinSleep
+		}
+		if b.sleep > b.MaxSleep {
+			b.sleep = b.MaxSleep
+		}
+
+		t := time.NewTimer(b.sleep)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+		case <-t.C:
+		}
+	}
+
+	b.n++
+	return ctx.Err()
+}
+
+// Bridge exposes Ring as blocking send/receive API for goroutines
+// which are not bound to EAL lcores, e.g. control plane. Blocking
+// operations wait with Backoff and are cancelled via
+// context.Context. Enqueue and dequeue operations follow the default
+// sync mode of the ring.
+//
+// Objects in the ring are invisible to the Go garbage collector. If
+// they point to Go memory, the sender must keep them referenced
+// elsewhere until they are received.
+type Bridge struct {
+	r       *Ring
+	backoff Backoff
+	batch   int
+}
+
+// BridgeOption alters Bridge behaviour.
+type BridgeOption struct {
+	f func(*Bridge)
+}
+
+// OptBackoff specifies backoff used while waiting for the ring.
+func OptBackoff(b Backoff) BridgeOption {
+	return BridgeOption{func(br *Bridge) {
+		br.backoff = b
+	}}
+}
+
+// OptBatch specifies maximum number of objects dequeued at once by
+// Chan.
+func OptBatch(n int) BridgeOption {
+	return BridgeOption{func(br *Bridge) {
+		br.batch = n
+	}}
+}
+
+// NewBridge creates Bridge over ring r.
+func NewBridge(r *Ring, opts ...BridgeOption) *Bridge {
+	br := &Bridge{r: r, backoff: DefaultBackoff, batch: 32}
+	for i := range opts {
+		opts[i].f(br)
+	}
+	return br
+}
+
+// Ring returns the underlying ring.
+func (br *Bridge) Ring() *Ring {
+	return br.r
+}
+
+// Send enqueues obj into the ring waiting for free space until ctx is
+// done.
+func (br *Bridge) Send(ctx context.Context, obj unsafe.Pointer) error {
+	_, err := br.SendBatch(ctx, []unsafe.Pointer{obj})
+	return err
+}
+
+// SendBatch enqueues all objs into the ring in bursts waiting for free
+// space until ctx is done. Returns number of enqueued objects which is
+// less than len(objs) only if error is returned.
+func (br *Bridge) SendBatch(ctx context.Context, objs []unsafe.Pointer) (int, error) {
+	b := br.backoff
+	sent := 0
+	for sent < len(objs) {
+		if n, _ := br.r.EnqueueBurst(objs[sent:]); n > 0 {
+			sent += int(n)
+			b.Reset()
+			continue
+		}
+
+		if err := b.Wait(ctx); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// Recv dequeues single object from the ring waiting for it until ctx
+// is done.
+func (br *Bridge) Recv(ctx context.Context) (unsafe.Pointer, error) {
+	objs := []unsafe.Pointer{nil}
+	_, err := br.RecvBatch(ctx, objs)
+	return objs[0], err
+}
+
+// RecvBatch waits until there are objects in the ring or ctx is done
+// and dequeues up to len(objs) of them. Returns number of dequeued
+// objects.
+func (br *Bridge) RecvBatch(ctx context.Context, objs []unsafe.Pointer) (int, error) {
+	b := br.backoff
+	for {
+		if n, _ := br.r.DequeueBurst(objs); n > 0 {
+			return int(n), nil
+		}
+
+		if err := b.Wait(ctx); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Chan starts a goroutine which dequeues objects from the ring in
+// batches and sends them into returned channel. The channel is closed
+// once ctx is done. No more objects are dequeued than the channel can
+// buffer so that none of them is dropped on cancellation: the caller
+// should drain the channel until it's closed, objects not consumed by
+// then remain in the ring.
+func (br *Bridge) Chan(ctx context.Context) <-chan unsafe.Pointer {
+	ch := make(chan unsafe.Pointer, br.batch)
+	go func() {
+		defer close(ch)
+		objs := make([]unsafe.Pointer, br.batch)
+		b := br.backoff
+		for {
+			// the goroutine is the only sender so free space
+			// may only grow until objects are sent
+			free := cap(ch) - len(ch)
+			if free == 0 {
+				if err := b.Wait(ctx); err != nil {
+					return
+				}
+				continue
+			}
+			b.Reset()
+
+			n, err := br.RecvBatch(ctx, objs[:free])
+			if err != nil {
+				return
+			}
+
+			for _, obj := range objs[:n] {
+				ch <- obj
+			}
+		}
+	}()
+	return ch
+}
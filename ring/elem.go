@@ -0,0 +1,222 @@
+package ring
+
+/*
+#include <stdlib.h>
+
+#include <rte_config.h>
+#include <rte_ring.h>
+#include <rte_ring_elem.h>
+
+#include "ring.h"
+*/
+import "C"
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// ElemRing is a ring of elements of type T stored by value. The size
+// of T must be a multiple of 4 and T must not contain Go pointers.
+//
+// All Ring methods are available via Ring().
+type ElemRing[T any] C.struct_rte_ring
+
+func esize[T any]() uint {
+	var zero T
+	return uint(unsafe.Sizeof(zero))
+}
+
+func checkEsize[T any]() error {
+	if sz := esize[T](); sz == 0 || sz%4 != 0 {
+		return syscall.EINVAL
+	}
+	return nil
+}
+
+func elemArgs[T any](r *ElemRing[T], obj []T) (*C.struct_rte_ring,
+	C.uintptr_t, C.uint, C.uint) {
+	return (*C.struct_rte_ring)(unsafe.Pointer(r)), C.uintptr_t(uintptr(unsafe.Pointer(&obj[0]))),
+		C.uint(esize[T]()), C.uint(len(obj))
+}
+
+// CreateElem creates new ring of elements of type T named name in
+// memory. See Create for details.
+func CreateElem[T any](name string, count uint, opts ...Option) (*ElemRing[T], error) {
+	if e := checkEsize[T](); e != nil {
+		return nil, e
+	}
+
+	rc := makeOpts(name, opts)
+	r := (*ElemRing[T])(C.rte_ring_create_elem(rc.cname, C.uint(esize[T]()),
+		C.uint(count), rc.socket, rc.flags))
+	if r == nil {
+		return nil, err()
+	}
+	return r, nil
+}
+
+// NewElem allocates and initializes ElemRing in Go memory. See New
+// for details.
+func NewElem[T any](name string, count uint, opts ...Option) (*ElemRing[T], error) {
+	if e := checkEsize[T](); e != nil {
+		return nil, e
+	}
+
+	size, err := GetMemSizeElem(esize[T](), count)
+	if err != nil {
+		return nil, err
+	}
+
+	p := make([]byte, size)
+	r := (*Ring)(unsafe.Pointer(&p[0]))
+	return (*ElemRing[T])(unsafe.Pointer(r)), r.Init(name, count, opts...)
+}
+
+// GetMemSizeElem calculates the memory size needed for a ring of
+// count elements of esize bytes each. esize must be a multiple of 4.
+// See GetMemSize for details.
+func GetMemSizeElem(esize, count uint) (int, error) {
+	sz := C.rte_ring_get_memsize_elem(C.uint(esize), C.uint(count))
+	return common.IntOrErr(int(sz))
+}
+
+// Ring returns the ring as Ring to be used for non-typed operations
+// like Count or Free. Enqueue and dequeue methods of Ring must not be
+// used unless T is of pointer size.
+func (r *ElemRing[T]) Ring() *Ring {
+	return (*Ring)(unsafe.Pointer(r))
+}
+
+// Free deallocates all memory used by the ring.
+func (r *ElemRing[T]) Free() {
+	r.Ring().Free()
+}
+
+// Enqueue enqueues an element into given ElemRing.
+func (r *ElemRing[T]) Enqueue(obj T) bool {
+	n, _ := r.EnqueueBulk([]T{obj})
+	return n != 0
+}
+
+// SpEnqueue enqueues an element into given ElemRing.
+func (r *ElemRing[T]) SpEnqueue(obj T) bool {
+	n, _ := r.SpEnqueueBulk([]T{obj})
+	return n != 0
+}
+
+// MpEnqueue enqueues an element into given ElemRing.
+func (r *ElemRing[T]) MpEnqueue(obj T) bool {
+	n, _ := r.MpEnqueueBulk([]T{obj})
+	return n != 0
+}
+
+// MpEnqueueBulk enqueues given elements from slice into ElemRing.
+// Returns number of enqueued elements (either 0 or len(obj)) and
+// amount of space in the ring after the enqueue operation has
+// finished.
+func (r *ElemRing[T]) MpEnqueueBulk(obj []T) (n, free uint32) {
+	return ret(C.mp_enqueue_bulk_elem(elemArgs(r, obj)))
+}
+
+// SpEnqueueBulk enqueues given elements from slice into ElemRing.
+// Returns number of enqueued elements (either 0 or len(obj)) and
+// amount of space in the ring after the enqueue operation has
+// finished.
+func (r *ElemRing[T]) SpEnqueueBulk(obj []T) (n, free uint32) {
+	return ret(C.sp_enqueue_bulk_elem(elemArgs(r, obj)))
+}
+
+// EnqueueBulk enqueues given elements from slice into ElemRing.
+// Returns number of enqueued elements (either 0 or len(obj)) and
+// amount of space in the ring after the enqueue operation has
+// finished.
+func (r *ElemRing[T]) EnqueueBulk(obj []T) (n, free uint32) {
+	return ret(C.enqueue_bulk_elem(elemArgs(r, obj)))
+}
+
+// MpEnqueueBurst enqueues given elements from slice into ElemRing.
+// Returns number of enqueued elements and amount of space in the ring
+// after the enqueue operation has finished.
+func (r *ElemRing[T]) MpEnqueueBurst(obj []T) (n, free uint32) {
+	return ret(C.mp_enqueue_burst_elem(elemArgs(r, obj)))
+}
+
+// SpEnqueueBurst enqueues given elements from slice into ElemRing.
+// Returns number of enqueued elements and amount of space in the ring
+// after the enqueue operation has finished.
+func (r *ElemRing[T]) SpEnqueueBurst(obj []T) (n, free uint32) {
+	return ret(C.sp_enqueue_burst_elem(elemArgs(r, obj)))
+}
+
+// EnqueueBurst enqueues given elements from slice into ElemRing.
+// Returns number of enqueued elements and amount of space in the ring
+// after the enqueue operation has finished.
+func (r *ElemRing[T]) EnqueueBurst(obj []T) (n, free uint32) {
+	return ret(C.enqueue_burst_elem(elemArgs(r, obj)))
+}
+
+// Dequeue dequeues single element from ElemRing.
+func (r *ElemRing[T]) Dequeue() (T, bool) {
+	objs := make([]T, 1)
+	n, _ := r.DequeueBulk(objs)
+	return objs[0], n != 0
+}
+
+// ScDequeue dequeues single element from ElemRing.
+func (r *ElemRing[T]) ScDequeue() (T, bool) {
+	objs := make([]T, 1)
+	n, _ := r.ScDequeueBulk(objs)
+	return objs[0], n != 0
+}
+
+// McDequeue dequeues single element from ElemRing.
+func (r *ElemRing[T]) McDequeue() (T, bool) {
+	objs := make([]T, 1)
+	n, _ := r.McDequeueBulk(objs)
+	return objs[0], n != 0
+}
+
+// McDequeueBulk dequeues elements into given slice. Returns number of
+// dequeued elements (either 0 or len(obj)) and amount of remaining
+// ring entries in the ring after the dequeue operation has finished.
+func (r *ElemRing[T]) McDequeueBulk(obj []T) (n, avail uint32) {
+	return ret(C.mc_dequeue_bulk_elem(elemArgs(r, obj)))
+}
+
+// ScDequeueBulk dequeues elements into given slice. Returns number of
+// dequeued elements (either 0 or len(obj)) and amount of remaining
+// ring entries in the ring after the dequeue operation has finished.
+func (r *ElemRing[T]) ScDequeueBulk(obj []T) (n, avail uint32) {
+	return ret(C.sc_dequeue_bulk_elem(elemArgs(r, obj)))
+}
+
+// DequeueBulk dequeues elements into given slice. Returns number of
+// dequeued elements (either 0 or len(obj)) and amount of remaining
+// ring entries in the ring after the dequeue operation has finished.
+func (r *ElemRing[T]) DequeueBulk(obj []T) (n, avail uint32) {
+	return ret(C.dequeue_bulk_elem(elemArgs(r, obj)))
+}
+
+// McDequeueBurst dequeues elements into given slice. Returns number
+// of dequeued elements and amount of remaining ring entries in the
+// ring after the dequeue operation has finished.
+func (r *ElemRing[T]) McDequeueBurst(obj []T) (n, avail uint32) {
+	return ret(C.mc_dequeue_burst_elem(elemArgs(r, obj)))
+}
+
+// ScDequeueBurst dequeues elements into given slice. Returns number
+// of dequeued elements and amount of remaining ring entries in the
+// ring after the dequeue operation has finished.
+func (r *ElemRing[T]) ScDequeueBurst(obj []T) (n, avail uint32) {
+	return ret(C.sc_dequeue_burst_elem(elemArgs(r, obj)))
+}
+
+// DequeueBurst dequeues elements into given slice. Returns number of
+// dequeued elements and amount of remaining ring entries in the ring
+// after the dequeue operation has finished.
+func (r *ElemRing[T]) DequeueBurst(obj []T) (n, avail uint32) {
+	return ret(C.dequeue_burst_elem(elemArgs(r, obj)))
+}
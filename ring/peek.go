@@ -0,0 +1,140 @@
+package ring
+
+/*
+#define ALLOW_EXPERIMENTAL_API
+#include <stdlib.h>
+
+#include <rte_config.h>
+#include <rte_ring.h>
+#include <rte_ring_elem.h>
+
+#include "ring.h"
+
+GO_RING_FUNC(dequeue_bulk_start)
+GO_RING_FUNC(dequeue_burst_start)
+
+static struct compound_int enqueue_bulk_start(struct rte_ring *r, unsigned int n) {
+	struct compound_int out;
+	out.rc = rte_ring_enqueue_bulk_start(r, n, &out.n);
+	return out;
+}
+
+static struct compound_int enqueue_burst_start(struct rte_ring *r, unsigned int n) {
+	struct compound_int out;
+	out.rc = rte_ring_enqueue_burst_start(r, n, &out.n);
+	return out;
+}
+
+static void enqueue_finish(struct rte_ring *r, uintptr_t objs, unsigned int n) {
+	rte_ring_enqueue_finish(r, (void **)objs, n);
+}
+
+static struct compound_int dequeue_bulk_start_elem(struct rte_ring *r,
+    uintptr_t objs, unsigned int esize, unsigned int n) {
+	struct compound_int out;
+	out.rc = rte_ring_dequeue_bulk_elem_start(r, (void *)objs, esize, n, &out.n);
+	return out;
+}
+
+static struct compound_int dequeue_burst_start_elem(struct rte_ring *r,
+    uintptr_t objs, unsigned int esize, unsigned int n) {
+	struct compound_int out;
+	out.rc = rte_ring_dequeue_burst_elem_start(r, (void *)objs, esize, n, &out.n);
+	return out;
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// Peek API allows to split enqueue or dequeue operation into two
+// phases. On start phase objects are reserved in the ring, and on
+// finish phase the operation is either committed or aborted. It
+// is available only for rings with SyncST or SyncMTHTS mode of the
+// producer (for enqueue) or consumer (for dequeue). No other
+// operation of the same kind is allowed in between.
+
+// EnqueueBulkStart reserves space for exactly n objects in the ring.
+// Returns n on success or 0, and amount of space in the ring after
+// the reservation. The operation must be completed with EnqueueFinish
+// or EnqueueAbort.
+func (r *Ring) EnqueueBulkStart(n uint32) (reserved, free uint32) {
+	return ret(C.enqueue_bulk_start((*C.struct_rte_ring)(r), C.uint(n)))
+}
+
+// EnqueueBurstStart reserves space for up to n objects in the ring.
+// Returns number of reserved entries and amount of space in the ring
+// after the reservation. The operation must be completed with
+// EnqueueFinish or EnqueueAbort.
+func (r *Ring) EnqueueBurstStart(n uint32) (reserved, free uint32) {
+	return ret(C.enqueue_burst_start((*C.struct_rte_ring)(r), C.uint(n)))
+}
+
+// EnqueueFinish completes enqueue started with EnqueueBulkStart or
+// EnqueueBurstStart. obj must contain at most reserved objects, the
+// rest of reservation is released.
+func (r *Ring) EnqueueFinish(obj []unsafe.Pointer) {
+	if len(obj) == 0 {
+		r.EnqueueAbort()
+		return
+	}
+	C.enqueue_finish(args(r, obj))
+}
+
+// EnqueueAbort releases the space reserved with EnqueueBulkStart or
+// EnqueueBurstStart.
+func (r *Ring) EnqueueAbort() {
+	C.rte_ring_enqueue_finish((*C.struct_rte_ring)(r), nil, 0)
+}
+
+// DequeueBulkStart peeks exactly len(obj) objects from the ring into
+// given slice without removing them. Returns number of peeked objects
+// (either 0 or len(obj)) and amount of remaining ring entries. The
+// operation must be completed with DequeueFinish or DequeueAbort.
+func (r *Ring) DequeueBulkStart(obj []unsafe.Pointer) (n, avail uint32) {
+	return ret(C.dequeue_bulk_start(args(r, obj)))
+}
+
+// DequeueBurstStart peeks up to len(obj) objects from the ring into
+// given slice without removing them. Returns number of peeked objects
+// and amount of remaining ring entries. The operation must be
+// completed with DequeueFinish or DequeueAbort.
+func (r *Ring) DequeueBurstStart(obj []unsafe.Pointer) (n, avail uint32) {
+	return ret(C.dequeue_burst_start(args(r, obj)))
+}
+
+// DequeueFinish commits removal of first n peeked objects from the
+// ring. The rest of peeked objects stay in the ring.
+func (r *Ring) DequeueFinish(n uint32) {
+	C.rte_ring_dequeue_finish((*C.struct_rte_ring)(r), C.uint(n))
+}
+
+// DequeueAbort leaves all peeked objects in the ring.
+func (r *Ring) DequeueAbort() {
+	r.DequeueFinish(0)
+}
+
+// DequeueBulkStart peeks exactly len(obj) elements from the ring into
+// given slice without removing them. See Ring.DequeueBulkStart.
+func (r *ElemRing[T]) DequeueBulkStart(obj []T) (n, avail uint32) {
+	return ret(C.dequeue_bulk_start_elem(elemArgs(r, obj)))
+}
+
+// DequeueBurstStart peeks up to len(obj) elements from the ring into
+// given slice without removing them. See Ring.DequeueBurstStart.
+func (r *ElemRing[T]) DequeueBurstStart(obj []T) (n, avail uint32) {
+	return ret(C.dequeue_burst_start_elem(elemArgs(r, obj)))
+}
+
+// DequeueFinish commits removal of first n peeked elements from the
+// ring. The rest of peeked elements stay in the ring.
+func (r *ElemRing[T]) DequeueFinish(n uint32) {
+	C.rte_ring_dequeue_elem_finish((*C.struct_rte_ring)(unsafe.Pointer(r)), C.uint(n))
+}
+
+// DequeueAbort leaves all peeked elements in the ring.
+func (r *ElemRing[T]) DequeueAbort() {
+	r.DequeueFinish(0)
+}
@@ -33,5 +33,31 @@ GO_RING_FUNC(sp_enqueue_bulk)
 GO_RING_FUNC(enqueue_burst)
 GO_RING_FUNC(enqueue_bulk)
 
+#define GO_RING_ELEM_FUNC(func)                            \
+static struct compound_int func ## _elem(struct rte_ring *r, \
+    uintptr_t objs, unsigned int esize, unsigned int n) {  \
+  struct compound_int out;                                 \
+  void *obj_table = (void *)objs;                          \
+  out.rc = rte_ring_ ## func ## _elem(r, obj_table, esize, \
+      n, &out.n);                                          \
+  return out;                                              \
+}
+
+// wrap dequeue elem API
+GO_RING_ELEM_FUNC(mc_dequeue_burst)
+GO_RING_ELEM_FUNC(mc_dequeue_bulk)
+GO_RING_ELEM_FUNC(sc_dequeue_burst)
+GO_RING_ELEM_FUNC(sc_dequeue_bulk)
+GO_RING_ELEM_FUNC(dequeue_burst)
+GO_RING_ELEM_FUNC(dequeue_bulk)
+
+// wrap enqueue elem API
+GO_RING_ELEM_FUNC(mp_enqueue_burst)
+GO_RING_ELEM_FUNC(mp_enqueue_bulk)
+GO_RING_ELEM_FUNC(sp_enqueue_burst)
+GO_RING_ELEM_FUNC(sp_enqueue_bulk)
+GO_RING_ELEM_FUNC(enqueue_burst)
+GO_RING_ELEM_FUNC(enqueue_bulk)
+
 #endif /* _RING_H_ */
 
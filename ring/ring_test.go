@@ -1,9 +1,11 @@
 package ring_test
 
 import (
+	"context"
 	"sync"
 	"syscall"
 	"testing"
+	"time"
 	"unsafe"
 
 	"github.com/tianyuansun/go-dpdk/common"
@@ -139,6 +141,393 @@ func TestRingNew(t *testing.T) {
 	assert(!ok)
 }
 
+type flowKey struct {
+	SrcIP, DstIP     uint32
+	SrcPort, DstPort uint16
+}
+
+type badElem struct {
+	a [3]byte
+}
+
+func TestElemRing(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	err := eal.ExecOnMain(func(ctx *eal.LcoreCtx) {
+		_, err := ring.CreateElem[badElem]("test_elem_ring_bad", 64)
+		assert(err == syscall.EINVAL, err)
+
+		r, err := ring.CreateElem[flowKey]("test_elem_ring", 64,
+			ring.OptSocket(eal.SocketID()))
+		assert(r != nil && err == nil, err)
+		defer r.Free()
+
+		r1, err := ring.Lookup("test_elem_ring")
+		assert(r.Ring() == r1 && err == nil, err)
+		assert(r.Ring().Cap() == 63, r.Ring().Cap())
+
+		keys := make([]flowKey, 40)
+		for i := range keys {
+			keys[i] = flowKey{SrcIP: uint32(i), DstIP: ^uint32(i), SrcPort: uint16(i), DstPort: 80}
+		}
+
+		n, free := r.MpEnqueueBulk(keys)
+		assert(n == 40 && free == 23, n, free)
+
+		// bulk is all or nothing
+		n, free = r.SpEnqueueBulk(keys)
+		assert(n == 0 && free == 23, n, free)
+
+		// burst enqueues as many as possible
+		n, free = r.EnqueueBurst(keys)
+		assert(n == 23 && free == 0, n, free)
+		assert(!r.Enqueue(keys[0]))
+
+		out := make([]flowKey, 40)
+		n, avail := r.McDequeueBulk(out)
+		assert(n == 40 && avail == 23, n, avail)
+		for i := range out {
+			assert(out[i] == keys[i], i, out[i])
+		}
+
+		n, avail = r.ScDequeueBulk(out)
+		assert(n == 0 && avail == 23, n, avail)
+
+		n, avail = r.DequeueBurst(out)
+		assert(n == 23 && avail == 0, n, avail)
+		for i := 0; i < int(n); i++ {
+			assert(out[i] == keys[i], i, out[i])
+		}
+
+		assert(r.SpEnqueue(keys[7]))
+		assert(r.MpEnqueue(keys[8]))
+		k, ok := r.ScDequeue()
+		assert(ok && k == keys[7], k)
+		k, ok = r.McDequeue()
+		assert(ok && k == keys[8], k)
+		_, ok = r.Dequeue()
+		assert(!ok)
+		assert(r.Ring().IsEmpty())
+	})
+	assert(err == nil, err)
+}
+
+func TestElemRingNew(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	_, err := ring.GetMemSizeElem(6, 64)
+	assert(err != nil)
+
+	r, err := ring.NewElem[flowKey]("test_elem_ring", 64, ring.OptSP, ring.OptSC)
+	assert(r != nil && err == nil, err)
+
+	keys := make([]flowKey, int(r.Ring().Cap()))
+	for i := range keys {
+		keys[i].SrcPort = uint16(i)
+	}
+	n, _ := r.SpEnqueueBurst(keys)
+	assert(n == uint32(len(keys)), n)
+	assert(r.Ring().IsFull())
+
+	out := make([]flowKey, len(keys))
+	n, _ = r.ScDequeueBurst(out)
+	assert(n == uint32(len(keys)), n)
+	for i := range out {
+		assert(out[i] == keys[i], i)
+	}
+}
+
+func TestRingSyncModes(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	err := eal.ExecOnMain(func(ctx *eal.LcoreCtx) {
+		r, err := ring.Create("test_ring_rts", 1024,
+			ring.OptProdSync(ring.SyncMTRTS),
+			ring.OptConsSync(ring.SyncMTRTS))
+		assert(r != nil && err == nil, err)
+		defer r.Free()
+
+		assert(r.ProdSyncType() == ring.SyncMTRTS, r.ProdSyncType())
+		assert(r.ConsSyncType() == ring.SyncMTRTS, r.ConsSyncType())
+		assert(r.SetProdHTDMax(8) == nil)
+		assert(r.ProdHTDMax() == 8, r.ProdHTDMax())
+		assert(r.SetConsHTDMax(16) == nil)
+		assert(r.ConsHTDMax() == 16, r.ConsHTDMax())
+
+		assert(r.Enqueue(unsafe.Pointer(r)))
+		obj, ok := r.Dequeue()
+		assert(ok && obj == unsafe.Pointer(r))
+
+		r1, err := ring.Create("test_ring_st", 1024, ring.OptSP,
+			ring.OptProdSync(ring.SyncMTHTS), ring.OptConsSync(ring.SyncST))
+		assert(r1 != nil && err == nil, err)
+		defer r1.Free()
+
+		assert(r1.ProdSyncType() == ring.SyncMTHTS, r1.ProdSyncType())
+		assert(r1.ConsSyncType() == ring.SyncST, r1.ConsSyncType())
+		assert(r1.SetProdHTDMax(8) != nil)
+		assert(r1.ConsSyncType().String() == "ST")
+	})
+	assert(err == nil, err)
+}
+
+func TestRingPeek(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	err := eal.ExecOnMain(func(ctx *eal.LcoreCtx) {
+		r, err := ring.Create("test_ring_peek", 64,
+			ring.OptProdSync(ring.SyncMTHTS),
+			ring.OptConsSync(ring.SyncMTHTS))
+		assert(r != nil && err == nil, err)
+		defer r.Free()
+
+		array := make([]int, 10)
+		objs := make([]unsafe.Pointer, len(array))
+		for i := range array {
+			objs[i] = unsafe.Pointer(&array[i])
+		}
+
+		// reserve, then enqueue only a part of reservation
+		n, free := r.EnqueueBulkStart(10)
+		assert(n == 10 && free == 53, n, free)
+		r.EnqueueFinish(objs[:8])
+		assert(r.Count() == 8, r.Count())
+
+		n, _ = r.EnqueueBurstStart(100)
+		assert(n == 55, n)
+		r.EnqueueAbort()
+		assert(r.Count() == 8, r.Count())
+
+		r.EnqueueBulkStart(2)
+		r.EnqueueFinish(objs[8:])
+		assert(r.Count() == 10, r.Count())
+
+		// peek and abort
+		out := make([]unsafe.Pointer, 4)
+		n, avail := r.DequeueBulkStart(out)
+		assert(n == 4 && avail == 6, n, avail)
+		for i := range out {
+			assert(out[i] == objs[i], i)
+		}
+		r.DequeueAbort()
+		assert(r.Count() == 10, r.Count())
+
+		// peek and commit a part
+		n, _ = r.DequeueBurstStart(out)
+		assert(n == 4, n)
+		r.DequeueFinish(2)
+		assert(r.Count() == 8, r.Count())
+
+		obj, ok := r.Dequeue()
+		assert(ok && obj == objs[2])
+
+		// typed ring
+		er, err := ring.CreateElem[flowKey]("test_elem_ring_peek", 64,
+			ring.OptConsSync(ring.SyncST))
+		assert(er != nil && err == nil, err)
+		defer er.Free()
+
+		keys := []flowKey{{SrcPort: 1}, {SrcPort: 2}, {SrcPort: 3}}
+		er.EnqueueBulk(keys)
+
+		peek := make([]flowKey, 8)
+		n, _ = er.DequeueBurstStart(peek)
+		assert(n == 3 && peek[0] == keys[0], n)
+		er.DequeueFinish(1)
+
+		n, _ = er.DequeueBulkStart(peek[:2])
+		assert(n == 2 && peek[0] == keys[1] && peek[1] == keys[2], n)
+		er.DequeueAbort()
+		assert(er.Ring().Count() == 2, er.Ring().Count())
+	})
+	assert(err == nil, err)
+}
+
+func TestRingZeroCopy(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	err := eal.ExecOnMain(func(ctx *eal.LcoreCtx) {
+		r, err := ring.Create("test_ring_zc", 16, ring.OptSP, ring.OptSC)
+		assert(r != nil && err == nil, err)
+		defer r.Free()
+
+		array := make([]int, 15)
+		objs := make([]unsafe.Pointer, len(array))
+		for i := range array {
+			objs[i] = unsafe.Pointer(&array[i])
+		}
+
+		// no wrap-around
+		zc, free := r.EnqueueZcBulkStart(10)
+		assert(zc.Len() == 10 && free == 5, zc.Len(), free)
+		assert(len(zc.First) == 10 && len(zc.Second) == 0)
+		copy(zc.First, objs)
+		r.EnqueueZcFinish(10)
+
+		dzc, avail := r.DequeueZcBurstStart(16)
+		assert(dzc.Len() == 10 && avail == 0, dzc.Len(), avail)
+		for i := 0; i < dzc.Len(); i++ {
+			assert(*dzc.At(i) == objs[i], i)
+		}
+		r.DequeueZcFinish(10)
+		assert(r.IsEmpty())
+
+		// head is at 10 now, 10 entries wrap around the end of ring
+		zc, _ = r.EnqueueZcBulkStart(10)
+		assert(len(zc.First) == 6 && len(zc.Second) == 4, len(zc.First), len(zc.Second))
+		for i := 0; i < zc.Len(); i++ {
+			*zc.At(i) = objs[i]
+		}
+		r.EnqueueZcFinish(10)
+		assert(r.Count() == 10, r.Count())
+
+		// bulk fails if not enough entries
+		dzc, avail = r.DequeueZcBulkStart(11)
+		assert(dzc.Len() == 0 && avail == 10, dzc.Len(), avail)
+		r.DequeueZcFinish(0)
+
+		dzc, _ = r.DequeueZcBulkStart(10)
+		assert(len(dzc.First) == 6 && len(dzc.Second) == 4)
+		for i := 0; i < dzc.Len(); i++ {
+			assert(*dzc.At(i) == objs[i], i)
+		}
+		r.DequeueZcFinish(10)
+		assert(r.IsEmpty())
+
+		// typed ring, commit a part of reservation
+		er, err := ring.CreateElem[flowKey]("test_elem_ring_zc", 8, ring.OptSP, ring.OptSC)
+		assert(er != nil && err == nil, err)
+		defer er.Free()
+
+		keys := make([]flowKey, 5)
+		for i := range keys {
+			keys[i].SrcPort = uint16(i)
+		}
+		er.EnqueueBulk(keys)
+		er.DequeueBulk(keys)
+
+		ezc, _ := er.EnqueueZcBurstStart(7)
+		assert(len(ezc.First) == 3 && len(ezc.Second) == 4, len(ezc.First), len(ezc.Second))
+		for i := 0; i < ezc.Len(); i++ {
+			ezc.At(i).SrcPort = uint16(100 + i)
+		}
+		er.EnqueueZcFinish(5)
+		assert(er.Ring().Count() == 5, er.Ring().Count())
+
+		ezc, _ = er.DequeueZcBulkStart(5)
+		assert(ezc.Len() == 5)
+		for i := 0; i < ezc.Len(); i++ {
+			assert(ezc.At(i).SrcPort == uint16(100+i), i)
+		}
+		er.DequeueZcFinish(5)
+		assert(er.Ring().IsEmpty())
+	})
+	assert(err == nil, err)
+}
+
+func TestBridge(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	r, err := ring.New("test_ring_bridge", 16)
+	assert(r != nil && err == nil, err)
+
+	br := ring.NewBridge(r, ring.OptBatch(4), ring.OptBackoff(ring.Backoff{
+		Spins:    4,
+		Yields:   4,
+		MinSleep: time.Microsecond,
+		MaxSleep: 100 * time.Microsecond,
+	}))
+	assert(br.Ring() == r)
+
+	// nothing to receive
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	_, err = br.Recv(ctx)
+	cancel()
+	assert(err == context.DeadlineExceeded, err)
+
+	array := make([]int, 1000)
+	objs := make([]unsafe.Pointer, len(array))
+	for i := range array {
+		objs[i] = unsafe.Pointer(&array[i])
+	}
+
+	// ring is too small for all objects, sender waits for receiver
+	var wg sync.WaitGroup
+	var sent int
+	var sendErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sent, sendErr = br.SendBatch(context.Background(), objs)
+	}()
+
+	out := make([]unsafe.Pointer, 0, len(objs))
+	buf := make([]unsafe.Pointer, 8)
+	for len(out) < len(objs) {
+		n, err := br.RecvBatch(context.Background(), buf)
+		assert(n > 0 && err == nil, n, err)
+		out = append(out, buf[:n]...)
+	}
+	wg.Wait()
+	assert(sent == len(objs) && sendErr == nil, sent, sendErr)
+
+	for i := range out {
+		assert(out[i] == objs[i], i)
+	}
+
+	// sender gives up on full ring
+	_, err = br.SendBatch(context.Background(), objs[:int(r.Cap())])
+	assert(err == nil, err)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	err = br.Send(ctx, objs[0])
+	cancel()
+	assert(err == context.DeadlineExceeded, err)
+
+	// channel delivers queued objects in order
+	ctx, cancel = context.WithCancel(context.Background())
+	ch := br.Chan(ctx)
+	for i := 0; i < int(r.Cap()); i++ {
+		assert(<-ch == objs[i], i)
+	}
+
+	assert(br.Send(ctx, objs[42]) == nil)
+	assert(<-ch == objs[42])
+
+	cancel()
+	for range ch {
+	}
+	assert(r.IsEmpty())
+
+	// objects are not dropped on cancellation
+	_, err = br.SendBatch(context.Background(), objs[:10])
+	assert(err == nil, err)
+	ctx, cancel = context.WithCancel(context.Background())
+	ch = br.Chan(ctx)
+	for len(ch) < cap(ch) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	var got []unsafe.Pointer
+	for obj := range ch {
+		got = append(got, obj)
+	}
+	assert(len(got)+int(r.Count()) == 10, len(got), r.Count())
+	rest := make([]unsafe.Pointer, 10)
+	n, _ := r.DequeueBurst(rest)
+	got = append(got, rest[:n]...)
+	for i := range got {
+		assert(got[i] == objs[i], i)
+	}
+}
+
 func TestRingNewErr(t *testing.T) {
 	assert := common.Assert(t, true)
 
@@ -0,0 +1,128 @@
+package ring
+
+/*
+#define ALLOW_EXPERIMENTAL_API
+#include <rte_config.h>
+#include <rte_ring.h>
+*/
+import "C"
+
+// SyncType is a synchronization mode of ring producer or consumer.
+type SyncType int
+
+// Synchronization modes of ring producer or consumer.
+const (
+	// SyncMT is multi-thread safe mode (default).
+	SyncMT SyncType = C.RTE_RING_SYNC_MT
+	// SyncST is single thread only mode.
+	SyncST SyncType = C.RTE_RING_SYNC_ST
+	// SyncMTRTS is multi-thread relaxed tail sync mode. Tail is
+	// updated only by the last thread completing the operation which
+	// avoids waiting for preempted threads.
+	SyncMTRTS SyncType = C.RTE_RING_SYNC_MT_RTS
+	// SyncMTHTS is multi-thread head/tail sync mode. Only one thread
+	// at a time is allowed to perform the operation which makes peek
+	// API available for multi-thread rings.
+	SyncMTHTS SyncType = C.RTE_RING_SYNC_MT_HTS
+)
+
+// Ring creation flags for RTS and HTS modes.
+const (
+	// ProducerRTS specifies that default enqueue operation will use
+	// relaxed tail sync mode.
+	ProducerRTS uint = C.RING_F_MP_RTS_ENQ
+	// ConsumerRTS specifies that default dequeue operation will use
+	// relaxed tail sync mode.
+	ConsumerRTS uint = C.RING_F_MC_RTS_DEQ
+	// ProducerHTS specifies that default enqueue operation will use
+	// head/tail sync mode.
+	ProducerHTS uint = C.RING_F_MP_HTS_ENQ
+	// ConsumerHTS specifies that default dequeue operation will use
+	// head/tail sync mode.
+	ConsumerHTS uint = C.RING_F_MC_HTS_DEQ
+)
+
+const (
+	prodSyncMask = C.RING_F_SP_ENQ | C.RING_F_MP_RTS_ENQ | C.RING_F_MP_HTS_ENQ
+	consSyncMask = C.RING_F_SC_DEQ | C.RING_F_MC_RTS_DEQ | C.RING_F_MC_HTS_DEQ
+)
+
+func (st SyncType) String() string {
+	switch st {
+	case SyncMT:
+		return "MT"
+	case SyncST:
+		return "ST"
+	case SyncMTRTS:
+		return "MT_RTS"
+	case SyncMTHTS:
+		return "MT_HTS"
+	}
+	return "unknown"
+}
+
+// OptProdSync specifies synchronization mode of the producer. It
+// overrides SingleProducer, ProducerRTS and ProducerHTS flags.
+func OptProdSync(st SyncType) Option {
+	return Option{func(rc *ringConf) {
+		rc.flags &^= prodSyncMask
+		switch st {
+		case SyncST:
+			rc.flags |= C.RING_F_SP_ENQ
+		case SyncMTRTS:
+			rc.flags |= C.RING_F_MP_RTS_ENQ
+		case SyncMTHTS:
+			rc.flags |= C.RING_F_MP_HTS_ENQ
+		}
+	}}
+}
+
+// OptConsSync specifies synchronization mode of the consumer. It
+// overrides SingleConsumer, ConsumerRTS and ConsumerHTS flags.
+func OptConsSync(st SyncType) Option {
+	return Option{func(rc *ringConf) {
+		rc.flags &^= consSyncMask
+		switch st {
+		case SyncST:
+			rc.flags |= C.RING_F_SC_DEQ
+		case SyncMTRTS:
+			rc.flags |= C.RING_F_MC_RTS_DEQ
+		case SyncMTHTS:
+			rc.flags |= C.RING_F_MC_HTS_DEQ
+		}
+	}}
+}
+
+// ProdSyncType returns synchronization mode of the ring producer.
+func (r *Ring) ProdSyncType() SyncType {
+	return SyncType(C.rte_ring_get_prod_sync_type((*C.struct_rte_ring)(r)))
+}
+
+// ConsSyncType returns synchronization mode of the ring consumer.
+func (r *Ring) ConsSyncType() SyncType {
+	return SyncType(C.rte_ring_get_cons_sync_type((*C.struct_rte_ring)(r)))
+}
+
+// ProdHTDMax returns maximum allowed distance between producer head
+// and tail of RTS ring. math.MaxUint32 is returned for non-RTS ring.
+func (r *Ring) ProdHTDMax() uint32 {
+	return uint32(C.rte_ring_get_prod_htd_max((*C.struct_rte_ring)(r)))
+}
+
+// SetProdHTDMax sets maximum allowed distance between producer head
+// and tail of RTS ring. ENOTSUP is returned for non-RTS ring.
+func (r *Ring) SetProdHTDMax(v uint32) error {
+	return err(C.rte_ring_set_prod_htd_max((*C.struct_rte_ring)(r), C.uint32_t(v)))
+}
+
+// ConsHTDMax returns maximum allowed distance between consumer head
+// and tail of RTS ring. math.MaxUint32 is returned for non-RTS ring.
+func (r *Ring) ConsHTDMax() uint32 {
+	return uint32(C.rte_ring_get_cons_htd_max((*C.struct_rte_ring)(r)))
+}
+
+// SetConsHTDMax sets maximum allowed distance between consumer head
+// and tail of RTS ring. ENOTSUP is returned for non-RTS ring.
+func (r *Ring) SetConsHTDMax(v uint32) error {
+	return err(C.rte_ring_set_cons_htd_max((*C.struct_rte_ring)(r), C.uint32_t(v)))
+}
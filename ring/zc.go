@@ -0,0 +1,177 @@
+package ring
+
+/*
+#define ALLOW_EXPERIMENTAL_API
+#include <rte_config.h>
+#include <rte_ring.h>
+#include <rte_ring_elem.h>
+
+struct zc_result {
+	void *ptr1;
+	void *ptr2;
+	unsigned int n1;
+	unsigned int n;
+	unsigned int rc;
+};
+
+static struct zc_result enqueue_zc_start(struct rte_ring *r,
+    unsigned int esize, unsigned int n, int burst) {
+	struct rte_ring_zc_data zcd = { 0 };
+	struct zc_result out;
+	out.n = burst ?
+		rte_ring_enqueue_zc_burst_elem_start(r, esize, n, &zcd, &out.rc) :
+		rte_ring_enqueue_zc_bulk_elem_start(r, esize, n, &zcd, &out.rc);
+	out.ptr1 = zcd.ptr1;
+	out.ptr2 = zcd.ptr2;
+	out.n1 = zcd.n1;
+	return out;
+}
+
+static struct zc_result dequeue_zc_start(struct rte_ring *r,
+    unsigned int esize, unsigned int n, int burst) {
+	struct rte_ring_zc_data zcd = { 0 };
+	struct zc_result out;
+	out.n = burst ?
+		rte_ring_dequeue_zc_burst_elem_start(r, esize, n, &zcd, &out.rc) :
+		rte_ring_dequeue_zc_bulk_elem_start(r, esize, n, &zcd, &out.rc);
+	out.ptr1 = zcd.ptr1;
+	out.ptr2 = zcd.ptr2;
+	out.n1 = zcd.n1;
+	return out;
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// ZeroCopy describes ring entries reserved by zero-copy enqueue or
+// dequeue. Due to wrap-around the entries may span up to two
+// contiguous regions of the ring: First and Second. The slices point
+// directly into the ring memory and are valid only until the
+// operation is finished.
+//
+// Zero-copy API is available only for rings with SyncST or SyncMTHTS
+// mode of the producer (for enqueue) or consumer (for dequeue).
+type ZeroCopy[T any] struct {
+	First  []T
+	Second []T
+}
+
+// Len returns total number of reserved entries.
+func (zc *ZeroCopy[T]) Len() int {
+	return len(zc.First) + len(zc.Second)
+}
+
+// At returns pointer to i-th reserved entry.
+func (zc *ZeroCopy[T]) At(i int) *T {
+	if i < len(zc.First) {
+		return &zc.First[i]
+	}
+	return &zc.Second[i-len(zc.First)]
+}
+
+func makeZeroCopy[T any](out C.struct_zc_result) (zc ZeroCopy[T], rc uint32) {
+	if out.n > 0 {
+		zc.First = unsafe.Slice((*T)(out.ptr1), int(out.n1))
+	}
+	if n2 := int(out.n - out.n1); n2 > 0 {
+		zc.Second = unsafe.Slice((*T)(out.ptr2), n2)
+	}
+	return zc, uint32(out.rc)
+}
+
+func zcStart[T any](r *Ring, n uint32, burst, enqueue bool) (ZeroCopy[T], uint32) {
+	var zero T
+	var b C.int
+	if burst {
+		b = 1
+	}
+
+	cr := (*C.struct_rte_ring)(r)
+	esize := C.uint(unsafe.Sizeof(zero))
+	if enqueue {
+		return makeZeroCopy[T](C.enqueue_zc_start(cr, esize, C.uint(n), b))
+	}
+	return makeZeroCopy[T](C.dequeue_zc_start(cr, esize, C.uint(n), b))
+}
+
+// EnqueueZcBulkStart reserves exactly n entries in the ring for
+// zero-copy enqueue. Returns reserved entries (none on failure) and
+// amount of space in the ring after the reservation. Fill the entries
+// and complete the operation with EnqueueZcFinish.
+func (r *Ring) EnqueueZcBulkStart(n uint32) (zc ZeroCopy[unsafe.Pointer], free uint32) {
+	return zcStart[unsafe.Pointer](r, n, false, true)
+}
+
+// EnqueueZcBurstStart reserves up to n entries in the ring for
+// zero-copy enqueue. Returns reserved entries and amount of space in
+// the ring after the reservation. Fill the entries and complete the
+// operation with EnqueueZcFinish.
+func (r *Ring) EnqueueZcBurstStart(n uint32) (zc ZeroCopy[unsafe.Pointer], free uint32) {
+	return zcStart[unsafe.Pointer](r, n, true, true)
+}
+
+// EnqueueZcFinish commits first n entries reserved by zero-copy
+// enqueue. The rest of reservation is released.
+func (r *Ring) EnqueueZcFinish(n uint32) {
+	C.rte_ring_enqueue_zc_finish((*C.struct_rte_ring)(r), C.uint(n))
+}
+
+// DequeueZcBulkStart gives access to exactly n entries of the ring
+// for zero-copy dequeue. Returns the entries (none on failure) and
+// amount of remaining ring entries. Complete the operation with
+// DequeueZcFinish.
+func (r *Ring) DequeueZcBulkStart(n uint32) (zc ZeroCopy[unsafe.Pointer], avail uint32) {
+	return zcStart[unsafe.Pointer](r, n, false, false)
+}
+
+// DequeueZcBurstStart gives access to up to n entries of the ring for
+// zero-copy dequeue. Returns the entries and amount of remaining ring
+// entries. Complete the operation with DequeueZcFinish.
+func (r *Ring) DequeueZcBurstStart(n uint32) (zc ZeroCopy[unsafe.Pointer], avail uint32) {
+	return zcStart[unsafe.Pointer](r, n, true, false)
+}
+
+// DequeueZcFinish removes first n entries accessed by zero-copy
+// dequeue from the ring. The rest of entries stay in the ring.
+func (r *Ring) DequeueZcFinish(n uint32) {
+	C.rte_ring_dequeue_zc_finish((*C.struct_rte_ring)(r), C.uint(n))
+}
+
+// EnqueueZcBulkStart reserves exactly n elements in the ring for
+// zero-copy enqueue. See Ring.EnqueueZcBulkStart.
+func (r *ElemRing[T]) EnqueueZcBulkStart(n uint32) (zc ZeroCopy[T], free uint32) {
+	return zcStart[T](r.Ring(), n, false, true)
+}
+
+// EnqueueZcBurstStart reserves up to n elements in the ring for
+// zero-copy enqueue. See Ring.EnqueueZcBurstStart.
+func (r *ElemRing[T]) EnqueueZcBurstStart(n uint32) (zc ZeroCopy[T], free uint32) {
+	return zcStart[T](r.Ring(), n, true, true)
+}
+
+// EnqueueZcFinish commits first n elements reserved by zero-copy
+// enqueue. The rest of reservation is released.
+func (r *ElemRing[T]) EnqueueZcFinish(n uint32) {
+	r.Ring().EnqueueZcFinish(n)
+}
+
+// DequeueZcBulkStart gives access to exactly n elements of the ring
+// for zero-copy dequeue. See Ring.DequeueZcBulkStart.
+func (r *ElemRing[T]) DequeueZcBulkStart(n uint32) (zc ZeroCopy[T], avail uint32) {
+	return zcStart[T](r.Ring(), n, false, false)
+}
+
+// DequeueZcBurstStart gives access to up to n elements of the ring
+// for zero-copy dequeue. See Ring.DequeueZcBurstStart.
+func (r *ElemRing[T]) DequeueZcBurstStart(n uint32) (zc ZeroCopy[T], avail uint32) {
+	return zcStart[T](r.Ring(), n, true, false)
+}
+
+// DequeueZcFinish removes first n elements accessed by zero-copy
+// dequeue from the ring. The rest of elements stay in the ring.
+func (r *ElemRing[T]) DequeueZcFinish(n uint32) {
+	r.Ring().DequeueZcFinish(n)
+}
@@ -0,0 +1,6 @@
+package service
+
+/*
+#cgo pkg-config: libdpdk
+*/
+import "C"
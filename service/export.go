@@ -0,0 +1,21 @@
+package service
+
+/*
+#include <rte_config.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+//export goServiceFunc
+func goServiceFunc(arg unsafe.Pointer) C.int {
+	fn := services.Read(*(*common.ObjectID)(arg)).(Func)
+	if fn() {
+		return 1
+	}
+	return 0
+}
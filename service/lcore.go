@@ -0,0 +1,77 @@
+package service
+
+/*
+#define ALLOW_EXPERIMENTAL_API
+#include <rte_config.h>
+#include <rte_lcore.h>
+#include <rte_service.h>
+*/
+import "C"
+
+import (
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// LcoreAdd adds lcore to the set of service lcores. The lcore must
+// not be running any function.
+func LcoreAdd(lcore uint) error {
+	return err(C.rte_service_lcore_add(C.uint32_t(lcore)))
+}
+
+// LcoreDel removes lcore from the set of service lcores. The lcore
+// must be stopped.
+func LcoreDel(lcore uint) error {
+	return err(C.rte_service_lcore_del(C.uint32_t(lcore)))
+}
+
+// LcoreStart launches service runner on service lcore. It runs all
+// services mapped to the lcore until LcoreStop is called.
+func LcoreStart(lcore uint) error {
+	return err(C.rte_service_lcore_start(C.uint32_t(lcore)))
+}
+
+// LcoreStop stops service runner on service lcore. It fails with
+// EBUSY if the lcore is the only one running an enabled service.
+func LcoreStop(lcore uint) error {
+	return err(C.rte_service_lcore_stop(C.uint32_t(lcore)))
+}
+
+// LcoreResetAll stops all service lcores and removes them from the
+// set of service lcores. All service mappings are cleared.
+func LcoreResetAll() error {
+	return err(C.rte_service_lcore_reset_all())
+}
+
+// LcoreCount returns the number of service lcores.
+func LcoreCount() int {
+	return int(C.rte_service_lcore_count())
+}
+
+// LcoreList returns ids of service lcores.
+func LcoreList() ([]uint, error) {
+	var ids [C.RTE_MAX_LCORE]C.uint32_t
+	n := C.rte_service_lcore_list(&ids[0], C.RTE_MAX_LCORE)
+	if n < 0 {
+		return nil, err(n)
+	}
+
+	out := make([]uint, n)
+	for i := range out {
+		out[i] = uint(ids[i])
+	}
+	return out, nil
+}
+
+// LcoreCountServices returns the number of services mapped to
+// service lcore.
+func LcoreCountServices(lcore uint) (int, error) {
+	return common.IntOrErr(C.rte_service_lcore_count_services(C.uint32_t(lcore)))
+}
+
+// LcoreLoops returns the number of service runner loops executed by
+// service lcore.
+func LcoreLoops(lcore uint) (uint64, error) {
+	var v C.uint64_t
+	e := err(C.rte_service_lcore_attr_get(C.uint32_t(lcore), C.RTE_SERVICE_LCORE_ATTR_LOOPS, &v))
+	return uint64(v), e
+}
@@ -0,0 +1,331 @@
+/*
+Package service wraps RTE service cores framework.
+
+A service is a function registered by a component which is run
+periodically by service lcores or explicitly by application lcores.
+It suits housekeeping tasks like stats collection or timers which
+should not occupy a dedicated lcore.
+
+Please note that EAL lcores launched by go-dpdk run the Go function
+executor and thus cannot be started as service lcores. Reserve service
+lcores with EAL -S option or run services from Go lcore functions with
+ID.RunIterOnAppLcore.
+*/
+package service
+
+/*
+#define ALLOW_EXPERIMENTAL_API
+#include <stdio.h>
+#include <stdlib.h>
+#include <stdint.h>
+#include <errno.h>
+
+#include <rte_config.h>
+#include <rte_memory.h>
+#include <rte_service.h>
+#include <rte_service_component.h>
+
+extern int goServiceFunc(void *arg);
+
+static int32_t service_func(void *arg) {
+	return goServiceFunc(arg) ? 0 : -EAGAIN;
+}
+
+static int register_service(const char *name, rte_service_func fn, void *arg,
+		uint32_t caps, int socket, uint32_t *id) {
+	struct rte_service_spec spec = {
+		.callback = fn,
+		.callback_userdata = arg,
+		.capabilities = caps,
+		.socket_id = socket,
+	};
+	snprintf(spec.name, sizeof(spec.name), "%s", name);
+	return rte_service_component_register(&spec, id);
+}
+
+static int register_go_service(const char *name, void *arg,
+		uint32_t caps, int socket, uint32_t *id) {
+	return register_service(name, service_func, arg, caps, socket, id);
+}
+*/
+import "C"
+
+import (
+	"io"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// ID is the service identifier assigned on registration.
+type ID uint32
+
+// Func is a Go service function. It should return true if some work
+// was done and false otherwise.
+type Func func() bool
+
+// Stats is the service statistics. It is collected only if enabled
+// with SetStatsEnable.
+type Stats struct {
+	Cycles uint64 // Cycles spent in the service function.
+	Calls  uint64 // Number of service function calls.
+}
+
+type serviceConf struct {
+	socket C.int
+	caps   C.uint32_t
+}
+
+// Option alters service registration.
+type Option struct {
+	f func(*serviceConf)
+}
+
+const (
+	// MTSafe specifies that the service function may be run on
+	// multiple lcores concurrently.
+	MTSafe uint = C.RTE_SERVICE_CAP_MT_SAFE
+)
+
+// Shortcuts for service capabilities.
+var (
+	OptMTSafe = OptCapability(MTSafe)
+)
+
+// OptSocket specifies NUMA socket the service prefers to run on.
+func OptSocket(socket uint) Option {
+	return Option{func(sc *serviceConf) {
+		sc.socket = C.int(socket)
+	}}
+}
+
+// OptCapability adds service capability.
+func OptCapability(c uint) Option {
+	return Option{func(sc *serviceConf) {
+		sc.caps |= C.uint32_t(c)
+	}}
+}
+
+var (
+	services = common.NewRegistryArray()
+
+	// opaque arguments of Go services to release on unregister
+	goServices = struct {
+		sync.Mutex
+		m map[ID]*common.ObjectID
+	}{m: map[ID]*common.ObjectID{}}
+)
+
+func err(n ...interface{}) error {
+	if len(n) == 0 {
+		return common.RteErrno()
+	}
+
+	return common.IntToErr(n[0])
+}
+
+func boolErr(n C.int32_t) (bool, error) {
+	if n < 0 {
+		return false, err(n)
+	}
+	return n != 0, nil
+}
+
+func b2i(b bool) C.int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func register(name string, fn *[0]byte, arg unsafe.Pointer, opts []Option) (ID, error) {
+	sc := &serviceConf{socket: C.SOCKET_ID_ANY}
+	for i := range opts {
+		opts[i].f(sc)
+	}
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	var id C.uint32_t
+	var rc C.int
+	if fn == nil {
+		rc = C.register_go_service(cname, arg, sc.caps, sc.socket, &id)
+	} else {
+		rc = C.register_service(cname, fn, arg, sc.caps, sc.socket, &id)
+	}
+	return ID(id), err(rc)
+}
+
+// Register registers Go function fn as a service named name. The
+// service is initially stopped.
+//
+// Please note that fn is executed in a non-Go thread and each call
+// involves cgo overhead so it is not meant for fast path processing.
+func Register(name string, fn Func, opts ...Option) (ID, error) {
+	obj := services.Create(fn)
+	arg := (*common.ObjectID)(C.malloc(C.size_t(unsafe.Sizeof(obj))))
+	*arg = obj
+
+	id, e := register(name, nil, unsafe.Pointer(arg), opts)
+	if e != nil {
+		services.Delete(obj)
+		C.free(unsafe.Pointer(arg))
+		return 0, e
+	}
+
+	goServices.Lock()
+	goServices.m[id] = arg
+	goServices.Unlock()
+	return id, nil
+}
+
+// RegisterC registers C function fn with signature
+// int32_t (*)(void *) as a service named name. arg is passed to fn
+// on every call. The service is initially stopped.
+func RegisterC(name string, fn, arg unsafe.Pointer, opts ...Option) (ID, error) {
+	if fn == nil {
+		return 0, common.IntErr(-int64(syscall.EINVAL))
+	}
+	return register(name, (*[0]byte)(fn), arg, opts)
+}
+
+// Unregister unregisters the service. The service must not be mapped
+// to any lcore or running.
+func (id ID) Unregister() error {
+	if e := err(C.rte_service_component_unregister(C.uint32_t(id))); e != nil {
+		return e
+	}
+
+	goServices.Lock()
+	defer goServices.Unlock()
+	if arg, ok := goServices.m[id]; ok {
+		services.Delete(*arg)
+		C.free(unsafe.Pointer(arg))
+		delete(goServices.m, id)
+	}
+	return nil
+}
+
+// GetByName returns the service registered with specified name.
+func GetByName(name string) (ID, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	var id C.uint32_t
+	return ID(id), err(C.rte_service_get_by_name(cname, &id))
+}
+
+// Count returns the number of registered services.
+func Count() uint32 {
+	return uint32(C.rte_service_get_count())
+}
+
+// Name returns the name of the service.
+func (id ID) Name() string {
+	return C.GoString(C.rte_service_get_name(C.uint32_t(id)))
+}
+
+// IsMTSafe tests if the service may be run on multiple lcores
+// concurrently.
+func (id ID) IsMTSafe() bool {
+	return C.rte_service_probe_capability(C.uint32_t(id), C.RTE_SERVICE_CAP_MT_SAFE) != 0
+}
+
+// ComponentRunstateSet sets the runstate of the service as seen by
+// its component. The service is run only if both component and
+// application runstates are set to running.
+func (id ID) ComponentRunstateSet(run bool) error {
+	return err(C.rte_service_component_runstate_set(C.uint32_t(id), C.uint32_t(b2i(run))))
+}
+
+// RunstateSet sets the runstate of the service as seen by
+// application.
+func (id ID) RunstateSet(run bool) error {
+	return err(C.rte_service_runstate_set(C.uint32_t(id), C.uint32_t(b2i(run))))
+}
+
+// Runstate tells if the service is running, i.e. both component and
+// application runstates are set to running.
+func (id ID) Runstate() (bool, error) {
+	return boolErr(C.rte_service_runstate_get(C.uint32_t(id)))
+}
+
+// MayBeActive tells if the service may be currently running on any
+// service lcore. It's useful to check the service is quiesced after
+// stopping it.
+func (id ID) MayBeActive() (bool, error) {
+	return boolErr(C.rte_service_may_be_active(C.uint32_t(id)))
+}
+
+// SetRunstateMappedCheck enables or disables the check that the
+// service is mapped to at least one service lcore before it is run.
+// The check is enabled by default.
+func (id ID) SetRunstateMappedCheck(enable bool) error {
+	return err(C.rte_service_set_runstate_mapped_check(C.uint32_t(id), C.int32_t(b2i(enable))))
+}
+
+// MapLcoreSet enables or disables running the service on specified
+// service lcore.
+func (id ID) MapLcoreSet(lcore uint, enable bool) error {
+	return err(C.rte_service_map_lcore_set(C.uint32_t(id), C.uint32_t(lcore), C.uint32_t(b2i(enable))))
+}
+
+// MapLcoreGet tells if the service is mapped to specified service
+// lcore.
+func (id ID) MapLcoreGet(lcore uint) (bool, error) {
+	return boolErr(C.rte_service_map_lcore_get(C.uint32_t(id), C.uint32_t(lcore)))
+}
+
+// RunIterOnAppLcore runs one iteration of the service on the calling
+// lcore which is not a service lcore. If serialize is true and the
+// service is not MT safe, atomics are used to prevent concurrent
+// runs with service lcores.
+func (id ID) RunIterOnAppLcore(serialize bool) error {
+	return err(C.rte_service_run_iter_on_app_lcore(C.uint32_t(id), C.uint32_t(b2i(serialize))))
+}
+
+// SetStatsEnable enables or disables statistics collection for the
+// service.
+func (id ID) SetStatsEnable(enable bool) error {
+	return err(C.rte_service_set_stats_enable(C.uint32_t(id), C.int32_t(b2i(enable))))
+}
+
+// Stats returns statistics of the service. Since DPDK 22.11 the
+// statistics are kept per lcore and only service lcores are summed,
+// i.e. iterations run with RunIterOnAppLcore are not accounted.
+func (id ID) Stats() (s Stats, e error) {
+	var v C.uint64_t
+	if e = err(C.rte_service_attr_get(C.uint32_t(id), C.RTE_SERVICE_ATTR_CYCLES, &v)); e != nil {
+		return
+	}
+	s.Cycles = uint64(v)
+
+	if e = err(C.rte_service_attr_get(C.uint32_t(id), C.RTE_SERVICE_ATTR_CALL_COUNT, &v)); e != nil {
+		return
+	}
+	s.Calls = uint64(v)
+	return
+}
+
+// ResetStats resets statistics of the service.
+func (id ID) ResetStats() error {
+	return err(C.rte_service_attr_reset_all(C.uint32_t(id)))
+}
+
+// Dump writes information about the service into w.
+func (id ID) Dump(w io.Writer) error {
+	return common.DumpTo(w, func(fp unsafe.Pointer) {
+		C.rte_service_dump((*C.FILE)(fp), C.uint32_t(id))
+	})
+}
+
+// DumpAll writes information about all services and service lcores
+// into w.
+func DumpAll(w io.Writer) error {
+	return common.DumpTo(w, func(fp unsafe.Pointer) {
+		C.rte_service_dump((*C.FILE)(fp), C.UINT32_MAX)
+	})
+}
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/eal"
+	"github.com/tianyuansun/go-dpdk/service"
+)
+
+func TestServiceGo(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	calls := 0
+	id, err := service.Register("test_service", func() bool {
+		calls++
+		return calls%2 == 0
+	})
+	assert(err == nil, err)
+
+	_, err = service.Register("test_service", func() bool { return false })
+	assert(err != nil)
+
+	id1, err := service.GetByName("test_service")
+	assert(err == nil && id1 == id, err)
+	_, err = service.GetByName("test_service_nonexistent")
+	assert(err != nil)
+
+	assert(service.Count() >= 1)
+	assert(id.Name() == "test_service", id.Name())
+	assert(!id.IsMTSafe())
+
+	running, err := id.Runstate()
+	assert(err == nil && !running, err)
+
+	// the service is not mapped to any service lcore
+	assert(id.SetRunstateMappedCheck(false) == nil)
+	assert(id.ComponentRunstateSet(true) == nil)
+	assert(id.RunstateSet(true) == nil)
+	assert(id.SetStatsEnable(true) == nil)
+	running, err = id.Runstate()
+	assert(err == nil && running, err)
+
+	// run service from a worker lcore executing Go functions
+	const n = 10
+	var errs []error
+	err = eal.ExecOnLcore(eal.LcoresWorker()[0], func(*eal.LcoreCtx) {
+		for i := 0; i < n; i++ {
+			errs = append(errs, id.RunIterOnAppLcore(true))
+		}
+	})
+	assert(err == nil, err)
+	for _, e := range errs {
+		assert(e == nil, e)
+	}
+	assert(calls == n, calls)
+
+	// since DPDK 22.11 only calls on service lcores are counted
+	stats, err := id.Stats()
+	assert(err == nil, err)
+	assert(stats.Calls <= n, stats)
+	assert(id.ResetStats() == nil)
+	stats, err = id.Stats()
+	assert(err == nil && stats.Calls == 0, stats)
+
+	buf := &bytes.Buffer{}
+	assert(id.Dump(buf) == nil)
+	assert(strings.Contains(buf.String(), "test_service"), buf)
+
+	assert(id.RunstateSet(false) == nil)
+	err = eal.ExecOnMain(func(*eal.LcoreCtx) {
+		assert(id.RunIterOnAppLcore(true) != nil)
+	})
+	assert(err == nil, err)
+	assert(calls == n, calls)
+
+	assert(id.Unregister() == nil)
+	_, err = service.GetByName("test_service")
+	assert(err != nil)
+}
+
+func TestServiceOptions(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	id, err := service.Register("test_service_mt", func() bool { return true },
+		service.OptMTSafe, service.OptSocket(0))
+	assert(err == nil, err)
+	assert(id.IsMTSafe())
+	assert(id.Unregister() == nil)
+
+	_, err = service.RegisterC("test_service_c", nil, nil)
+	assert(err != nil)
+}
+
+func TestServiceLcores(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	// go-dpdk lcores are busy running Go executor
+	assert(service.LcoreCount() == 0)
+	ids, err := service.LcoreList()
+	assert(err == nil && len(ids) == 0, ids, err)
+}
@@ -0,0 +1,6 @@
+package stack
+
+/*
+#cgo pkg-config: libdpdk
+*/
+import "C"
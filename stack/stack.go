@@ -0,0 +1,195 @@
+/*
+Package stack wraps RTE stack library.
+
+Please refer to DPDK Programmer's Guide for reference and caveats.
+*/
+package stack
+
+/*
+#define ALLOW_EXPERIMENTAL_API
+#include <stdlib.h>
+#include <stdint.h>
+
+#include <rte_config.h>
+#include <rte_memory.h>
+#include <rte_stack.h>
+
+static unsigned int push_bulk(struct rte_stack *s, uintptr_t objs, unsigned int n) {
+	return rte_stack_push(s, (void * const *)objs, n);
+}
+
+static unsigned int pop_bulk(struct rte_stack *s, uintptr_t objs, unsigned int n) {
+	return rte_stack_pop(s, (void **)objs, n);
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// Stack is a fixed-size LIFO container of pointers. It has the
+// following features:
+//
+// * LIFO (Last In First Out)
+//
+// * Maximum size is fixed; the pointers are stored in a table or
+// in a linked list of preallocated elements.
+//
+// * Standard implementation protected by a spinlock or lock-free
+// implementation.
+//
+// * Bulk push and pop.
+type Stack C.struct_rte_stack
+
+type stackConf struct {
+	socket C.int
+	flags  C.uint32_t
+}
+
+// Option alters stack behaviour.
+type Option struct {
+	f func(*stackConf)
+}
+
+const (
+	// LockFree specifies that the stack should use lock-free
+	// implementation. It is supported only on platforms with 128-bit
+	// compare-and-swap, e.g. x86_64 and aarch64. It is not
+	// preemption safe as opposed to the standard implementation.
+	LockFree uint = C.RTE_STACK_F_LF
+)
+
+// Shortcuts for stack creation flags.
+var (
+	OptLF = OptFlag(LockFree)
+)
+
+func err(n ...interface{}) error {
+	if len(n) == 0 {
+		return common.RteErrno()
+	}
+
+	return common.IntToErr(n[0])
+}
+
+// OptSocket specifies the socket id where the memzone would be
+// created in Create.
+func OptSocket(socket uint) Option {
+	return Option{func(sc *stackConf) {
+		sc.socket = C.int(socket)
+	}}
+}
+
+// OptFlag add one of permitted flags for the stack creation.
+func OptFlag(flag uint) Option {
+	return Option{func(sc *stackConf) {
+		sc.flags |= C.uint32_t(flag)
+	}}
+}
+
+// Create creates new stack named name in memory.
+//
+// This function uses rte_memzone_reserve() to allocate memory. The
+// stack is able to hold exactly count objects.
+//
+// The stack is added in RTE_TAILQ_STACK list.
+func Create(name string, count uint, opts ...Option) (*Stack, error) {
+	sc := &stackConf{socket: C.SOCKET_ID_ANY}
+	for i := range opts {
+		opts[i].f(sc)
+	}
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	s := (*Stack)(C.rte_stack_create(cname, C.uint(count), sc.socket, sc.flags))
+	if s == nil {
+		return nil, err()
+	}
+	return s, nil
+}
+
+// Lookup searches a stack from its name in RTE_TAILQ_STACK.
+func Lookup(name string) (*Stack, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	s := (*Stack)(C.rte_stack_lookup(cname))
+	if s == nil {
+		return nil, err()
+	}
+	return s, nil
+}
+
+// Free deallocates all memory used by the stack.
+func (s *Stack) Free() {
+	C.rte_stack_free((*C.struct_rte_stack)(s))
+}
+
+// Name returns stack's name stored when creating.
+func (s *Stack) Name() string {
+	return C.GoString(&(*C.struct_rte_stack)(s).name[0])
+}
+
+// Cap returns the number of objects which can be stored in the stack.
+func (s *Stack) Cap() uint {
+	return uint((*C.struct_rte_stack)(s).capacity)
+}
+
+// IsLockFree tests if the stack uses lock-free implementation.
+func (s *Stack) IsLockFree() bool {
+	return (*C.struct_rte_stack)(s).flags&C.RTE_STACK_F_LF != 0
+}
+
+// Count returns the number of objects in the stack.
+func (s *Stack) Count() uint {
+	return uint(C.rte_stack_count((*C.struct_rte_stack)(s)))
+}
+
+// FreeCount returns the number of free entries in the stack.
+func (s *Stack) FreeCount() uint {
+	return uint(C.rte_stack_free_count((*C.struct_rte_stack)(s)))
+}
+
+// IsEmpty tests if the stack is empty.
+func (s *Stack) IsEmpty() bool {
+	return C.rte_stack_empty((*C.struct_rte_stack)(s)) != 0
+}
+
+func args(s *Stack, obj []unsafe.Pointer) (*C.struct_rte_stack,
+	C.uintptr_t, C.uint) {
+	return (*C.struct_rte_stack)(s), C.uintptr_t(uintptr(unsafe.Pointer(&obj[0]))), C.uint(len(obj))
+}
+
+// Push pushes an object onto the stack.
+func (s *Stack) Push(obj unsafe.Pointer) bool {
+	return s.PushBulk([]unsafe.Pointer{obj}) != 0
+}
+
+// Pop pops an object from the stack.
+func (s *Stack) Pop() (unsafe.Pointer, bool) {
+	objs := []unsafe.Pointer{nil}
+	n := s.PopBulk(objs)
+	return objs[0], n != 0
+}
+
+// PushBulk pushes given objects from slice onto the stack. The last
+// object in the slice will be popped first. Returns number of pushed
+// objects (either 0 or len(obj)).
+func (s *Stack) PushBulk(obj []unsafe.Pointer) uint32 {
+	if len(obj) == 0 {
+		return 0
+	}
+	return uint32(C.push_bulk(args(s, obj)))
+}
+
+// PopBulk pops objects from the stack into given slice. The most
+// recently pushed object is stored first. Returns number of popped
+// objects (either 0 or len(obj)).
+func (s *Stack) PopBulk(obj []unsafe.Pointer) uint32 {
+	if len(obj) == 0 {
+		return 0
+	}
+	return uint32(C.pop_bulk(args(s, obj)))
+}
@@ -0,0 +1,138 @@
+package stack_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/eal"
+	"github.com/tianyuansun/go-dpdk/stack"
+)
+
+func TestStackCreate(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	err := eal.ExecOnMain(func(ctx *eal.LcoreCtx) {
+		s, err := stack.Create("test_stack", 1024, stack.OptSocket(eal.SocketID()))
+		assert(s != nil && err == nil, err)
+		defer s.Free()
+
+		assert(s.Name() == "test_stack", s.Name())
+		assert(!s.IsLockFree())
+
+		s1, err := stack.Lookup("test_stack")
+		assert(s == s1 && err == nil)
+		_, err = stack.Lookup("test_stack_nonexistent")
+		assert(err != nil)
+
+		_, err = stack.Create("test_stack", 1024)
+		assert(err != nil)
+	})
+	assert(err == nil, err)
+}
+
+func testStackPushPop(t *testing.T, s *stack.Stack) {
+	assert := common.Assert(t, true)
+	n := int(s.Cap())
+
+	assert(s.IsEmpty())
+	assert(s.Count() == 0, s.Count())
+	assert(s.FreeCount() == uint(n), s.FreeCount())
+
+	array := make([]int, n)
+	objs := make([]unsafe.Pointer, n)
+	for i := range array {
+		objs[i] = unsafe.Pointer(&array[i])
+	}
+
+	assert(s.PushBulk(objs[:n-1]) == uint32(n-1))
+	assert(s.Count() == uint(n-1), s.Count())
+
+	// bulk is all or nothing
+	assert(s.PushBulk(objs[:2]) == 0)
+	assert(s.Push(objs[n-1]))
+	assert(s.FreeCount() == 0)
+	assert(!s.Push(objs[0]))
+
+	// LIFO order
+	obj, ok := s.Pop()
+	assert(ok && obj == objs[n-1])
+
+	out := make([]unsafe.Pointer, 4)
+	assert(s.PopBulk(out) == 4)
+	for i := range out {
+		assert(out[i] == objs[n-2-i], i)
+	}
+
+	out = make([]unsafe.Pointer, n)
+	assert(s.PopBulk(out) == 0)
+	assert(s.PopBulk(out[:n-5]) == uint32(n-5))
+	assert(s.IsEmpty())
+	_, ok = s.Pop()
+	assert(!ok)
+}
+
+func TestStackPushPop(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	err := eal.ExecOnMain(func(ctx *eal.LcoreCtx) {
+		s, err := stack.Create("test_stack_std", 64)
+		assert(s != nil && err == nil, err)
+		defer s.Free()
+		assert(s.Cap() == 64, s.Cap())
+		testStackPushPop(t, s)
+	})
+	assert(err == nil, err)
+}
+
+func TestStackLockFree(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+
+	var s *stack.Stack
+	var createErr error
+	err := eal.ExecOnMain(func(ctx *eal.LcoreCtx) {
+		if s, createErr = stack.Create("test_stack_lf", 64, stack.OptLF); createErr != nil {
+			return
+		}
+		assert(s.IsLockFree())
+		testStackPushPop(t, s)
+	})
+	assert(err == nil, err)
+	if createErr != nil {
+		// skip on the test goroutine, not on the lcore
+		t.Skip("lock-free stack is not supported:", createErr)
+	}
+	defer eal.ExecOnMain(func(*eal.LcoreCtx) { s.Free() })
+
+	// concurrent free-list usage from all lcores
+	array := make([]int, s.Cap())
+	objs := make([]unsafe.Pointer, len(array))
+	for i := range array {
+		objs[i] = unsafe.Pointer(&array[i])
+	}
+	assert(s.PushBulk(objs) == uint32(len(objs)))
+
+	lcores := eal.Lcores()
+	ch := make(chan error, len(lcores))
+	for _, id := range lcores {
+		eal.ExecOnLcoreAsync(id, ch, func(*eal.LcoreCtx) {
+			buf := make([]unsafe.Pointer, 4)
+			for i := 0; i < 10000; i++ {
+				if s.PopBulk(buf) != 0 {
+					for s.PushBulk(buf) == 0 {
+					}
+				}
+			}
+		})
+	}
+	for range lcores {
+		assert(<-ch == nil)
+	}
+	assert(s.Count() == s.Cap(), s.Count())
+}
@@ -0,0 +1,6 @@
+package timer
+
+/*
+#cgo pkg-config: libdpdk
+*/
+import "C"
@@ -0,0 +1,19 @@
+package timer
+
+/*
+#include <rte_config.h>
+#include <rte_timer.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+//export goTimerCb
+func goTimerCb(tim *C.struct_rte_timer, arg unsafe.Pointer) {
+	fn := timers.Read(*(*common.ObjectID)(arg)).(Func)
+	fn((*Timer)(tim))
+}
@@ -0,0 +1,218 @@
+/*
+Package timer wraps RTE timer library.
+
+Timers are run by rte_timer_manage which should be called
+periodically by each lcore running timers, e.g. in its polling loop.
+Expired timers invoke Go callbacks in the context of the lcore
+calling Manage.
+
+Please refer to DPDK Programmer's Guide for reference and caveats.
+*/
+package timer
+
+/*
+#include <stdio.h>
+#include <stdlib.h>
+#include <stdint.h>
+#include <errno.h>
+
+#include <rte_config.h>
+#include <rte_cycles.h>
+#include <rte_lcore.h>
+#include <rte_timer.h>
+
+extern void goTimerCb(struct rte_timer *tim, void *arg);
+
+// timer with Go callback object id
+struct go_timer {
+	struct rte_timer tim;
+	uint64_t obj;
+};
+
+static int timer_reset(struct rte_timer *tim, uint64_t ticks,
+		enum rte_timer_type type, unsigned lcore) {
+	struct go_timer *t = (struct go_timer *)tim;
+	return rte_timer_reset(tim, ticks, type, lcore, goTimerCb, &t->obj);
+}
+
+static void timer_reset_sync(struct rte_timer *tim, uint64_t ticks,
+		enum rte_timer_type type, unsigned lcore) {
+	struct go_timer *t = (struct go_timer *)tim;
+	rte_timer_reset_sync(tim, ticks, type, lcore, goTimerCb, &t->obj);
+}
+*/
+import "C"
+
+import (
+	"io"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/tianyuansun/go-dpdk/common"
+)
+
+// Timer is an RTE timer. It is allocated in C memory and should be
+// released with Free.
+type Timer C.struct_rte_timer
+
+// Func is a timer callback. It is called with expired timer on the
+// lcore the timer was set to run on.
+type Func func(*Timer)
+
+// Type is the timer type.
+type Type uint32
+
+// Timer types.
+const (
+	// Single is a one-shot timer.
+	Single Type = C.SINGLE
+	// Periodical is a timer automatically reloaded after
+	// expiration.
+	Periodical Type = C.PERIODICAL
+)
+
+// LcoreAny may be specified as the lcore to run the timer on, in
+// which case timer library chooses lcores in round-robin manner.
+const LcoreAny = C.LCORE_ID_ANY
+
+var (
+	timers = common.NewRegistryMap()
+)
+
+func err(n ...interface{}) error {
+	if len(n) == 0 {
+		return common.RteErrno()
+	}
+
+	return common.IntToErr(n[0])
+}
+
+// SubsystemInit initializes timer library. It must be called once
+// after EAL initialization and before any timer is used. Repeated
+// calls are not an error.
+func SubsystemInit() error {
+	if n := C.rte_timer_subsystem_init(); n < 0 && n != -C.EALREADY {
+		return err(n)
+	}
+	return nil
+}
+
+// SubsystemFinalize releases resources allocated by timer library.
+func SubsystemFinalize() {
+	C.rte_timer_subsystem_finalize()
+}
+
+// Manage runs expired timers of the calling lcore. It should be
+// called periodically by every lcore running timers.
+func Manage() error {
+	return err(C.rte_timer_manage())
+}
+
+// Hz returns the frequency of timer ticks.
+func Hz() uint64 {
+	return uint64(C.rte_get_timer_hz())
+}
+
+// Ticks converts duration d to timer ticks. Negative durations are
+// converted to zero as in cycles.FromDuration.
+func Ticks(d time.Duration) uint64 {
+	if d <= 0 {
+		return 0
+	}
+	hz := Hz()
+	return uint64(d/time.Second)*hz + uint64(d%time.Second)*hz/uint64(time.Second)
+}
+
+func (t *Timer) goTimer() *C.struct_go_timer {
+	return (*C.struct_go_timer)(unsafe.Pointer(t))
+}
+
+func (t *Timer) ctimer() *C.struct_rte_timer {
+	return (*C.struct_rte_timer)(t)
+}
+
+// New allocates and initializes a timer which calls fn on
+// expiration. The timer is stopped initially.
+func New(fn Func) *Timer {
+	gt := (*C.struct_go_timer)(C.malloc(C.sizeof_struct_go_timer))
+	gt.obj = C.uint64_t(timers.Create(fn))
+	C.rte_timer_init(&gt.tim)
+	return (*Timer)(unsafe.Pointer(gt))
+}
+
+// Free releases the timer. The timer must be stopped and must not be
+// used afterwards.
+//
+// Free must not be called from the timer's own callback: the timer
+// library updates the timer state after the callback returns, so it
+// would access freed memory. Stop the timer in the callback and free
+// it elsewhere instead.
+func (t *Timer) Free() {
+	timers.Delete(common.ObjectID(t.goTimer().obj))
+	C.free(unsafe.Pointer(t))
+}
+
+// SetFunc changes the timer callback. It takes effect on the next
+// expiration.
+func (t *Timer) SetFunc(fn Func) {
+	timers.Update(common.ObjectID(t.goTimer().obj), fn)
+}
+
+// ResetTicks starts or restarts the timer to expire in ticks on
+// specified lcore. Periodical timer is reloaded with the same ticks.
+//
+// If the timer is being run or modified on another lcore, EINPROGRESS
+// is returned.
+func (t *Timer) ResetTicks(ticks uint64, typ Type, lcore uint) error {
+	if C.timer_reset(t.ctimer(), C.uint64_t(ticks), C.enum_rte_timer_type(typ), C.uint(lcore)) < 0 {
+		return common.IntErr(-int64(syscall.EINPROGRESS))
+	}
+	return nil
+}
+
+// Reset is ResetTicks with timeout specified as a duration.
+func (t *Timer) Reset(d time.Duration, typ Type, lcore uint) error {
+	return t.ResetTicks(Ticks(d), typ, lcore)
+}
+
+// ResetTicksSync is ResetTicks which loops until the timer can be
+// reset.
+func (t *Timer) ResetTicksSync(ticks uint64, typ Type, lcore uint) {
+	C.timer_reset_sync(t.ctimer(), C.uint64_t(ticks), C.enum_rte_timer_type(typ), C.uint(lcore))
+}
+
+// ResetSync is ResetTicksSync with timeout specified as a duration.
+func (t *Timer) ResetSync(d time.Duration, typ Type, lcore uint) {
+	t.ResetTicksSync(Ticks(d), typ, lcore)
+}
+
+// Stop stops the timer. The callback is not called afterwards unless
+// the timer is reset.
+//
+// If the timer is being run or modified on another lcore, EINPROGRESS
+// is returned.
+func (t *Timer) Stop() error {
+	if C.rte_timer_stop(t.ctimer()) < 0 {
+		return common.IntErr(-int64(syscall.EINPROGRESS))
+	}
+	return nil
+}
+
+// StopSync is Stop which loops until the timer can be stopped.
+func (t *Timer) StopSync() {
+	C.rte_timer_stop_sync(t.ctimer())
+}
+
+// Pending tests if the timer is started and not expired yet.
+func (t *Timer) Pending() bool {
+	return C.rte_timer_pending(t.ctimer()) != 0
+}
+
+// DumpStats writes timer statistics into w. Statistics are collected
+// only if DPDK is built with RTE_LIBRTE_TIMER_DEBUG.
+func DumpStats(w io.Writer) error {
+	return common.DumpTo(w, func(fp unsafe.Pointer) {
+		C.rte_timer_dump_stats((*C.FILE)(fp))
+	})
+}
@@ -0,0 +1,104 @@
+package timer_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/tianyuansun/go-dpdk/common"
+	"github.com/tianyuansun/go-dpdk/eal"
+	"github.com/tianyuansun/go-dpdk/timer"
+)
+
+func manageUntil(cond func() bool, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		if timer.Manage() != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTimer(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+	assert(timer.SubsystemInit() == nil)
+	assert(timer.SubsystemInit() == nil)
+
+	assert(timer.Hz() > 0)
+	assert(timer.Ticks(time.Second) == timer.Hz())
+	assert(timer.Ticks(0) == 0)
+	assert(timer.Ticks(-time.Second) == 0)
+
+	lcore := eal.LcoresWorker()[0]
+	var fired, periodic int
+	var pendingBefore, pendingAfter, ok1, ok2 bool
+	var errs []error
+
+	err := eal.ExecOnLcore(lcore, func(*eal.LcoreCtx) {
+		tim := timer.New(func(tm *timer.Timer) {
+			fired++
+		})
+		defer tim.Free()
+
+		errs = append(errs, tim.Reset(time.Millisecond, timer.Single, lcore))
+		pendingBefore = tim.Pending()
+		ok1 = manageUntil(func() bool { return fired > 0 }, time.Second)
+		pendingAfter = tim.Pending()
+
+		// periodic timer stops itself from the callback
+		tim.SetFunc(func(tm *timer.Timer) {
+			if periodic++; periodic == 3 {
+				errs = append(errs, tm.Stop())
+			}
+		})
+		errs = append(errs, tim.Reset(time.Millisecond, timer.Periodical, lcore))
+		ok2 = manageUntil(func() bool { return !tim.Pending() }, time.Second)
+	})
+	assert(err == nil, err)
+	for _, e := range errs {
+		assert(e == nil, e)
+	}
+
+	assert(pendingBefore && !pendingAfter)
+	assert(ok1 && fired == 1, fired)
+	assert(ok2 && periodic == 3, periodic)
+}
+
+func TestTimerStop(t *testing.T) {
+	assert := common.Assert(t, true)
+
+	eal.InitOnceSafe("test", 4)
+	assert(timer.SubsystemInit() == nil)
+
+	fired := false
+	var pending bool
+	var errs []error
+	err := eal.ExecOnMain(func(*eal.LcoreCtx) {
+		tim := timer.New(func(*timer.Timer) { fired = true })
+		defer tim.Free()
+
+		errs = append(errs, tim.Reset(time.Millisecond, timer.Single, eal.LcoreID()))
+		errs = append(errs, tim.Stop())
+		pending = tim.Pending()
+
+		time.Sleep(2 * time.Millisecond)
+		errs = append(errs, timer.Manage())
+
+		tim.ResetTicksSync(0, timer.Single, eal.LcoreID())
+		tim.StopSync()
+	})
+	assert(err == nil, err)
+	for _, e := range errs {
+		assert(e == nil, e)
+	}
+	assert(!pending && !fired)
+
+	buf := &bytes.Buffer{}
+	assert(timer.DumpStats(buf) == nil)
+}